@@ -15,6 +15,8 @@
 //
 // *Please note that many microcomputer, PC and MS-DOS based boards used ANSI control
 // codes for colorizations that this library does not support.
+// The exception are the late-era PCBoard documents that mix @X codes with ANSI colors,
+// see [PCBoardANSIHTML].
 //
 // # PCBoard
 //
@@ -28,12 +30,20 @@
 // Another PC/MS-DOS application that was very popular with the hacking, phreaking,
 // and pirate communities in the early 1990s. It introduced a unique | pipe code
 // syntax in late 1991 that revised the code syntax in version 2 of the software.
+// This library supports the version 2 syntax, the 15 case sensitive color letters,
+// the |d default color that resets the foreground to grey or the swapped background to black,
+// the |S background swap, and the |! control that is removed from the output.
+// Like Renegade and WWIV, a vertical bar that is not followed by a valid code,
+// such as "||", "| " or "|x", is kept as literal text.
 //
 // # Renegade
 //
 // A PC/MS-DOS application that was a derivative of the source code of Telegard BBS.
 // Surprisingly there was a new release of this software in 2021. Renegade had two
 // methods to implement color, and this library uses the Pipe Bar Color Codes.
+// The recognized codes are the two digit |00 to |15 foreground colors, where |08 to |15
+// are the high intensity colors, and the |16 to |23 background colors. The pipe codes have
+// no separate intensity toggles, and any other vertical bar is kept as literal text.
 //
 // # Telegard
 //
@@ -49,13 +59,40 @@
 // to other platforms. The source is now Open Source and is still updated.
 // Confusingly WWIV has three methods of colorizing text, 10 Pipe colors, two-digit
 // pipe colors, and its original Heart Codes.
+// The |# pipe and the ♥ heart codes are always followed by a single digit, 0 to 9,
+// so |#12 is the color 1 followed by the literal text 2. The two-digit pipe colors
+// use the |00 syntax of Renegade, and they are rendered as Renegade codes.
 //
 // # Wildcat
 //
 // WILDCAT! was a popular, propriety PC/MS-DOS application from the late 1980s that
 // later migrated to Windows. It was one of the few BBS applications that sold at
 // retail in a physical box. It extensively used @ color codes throughout later
-// revisions of its software.
+// revisions of its software. This library only supports the @NN@ form of these codes,
+// an uppercase hexadecimal background and foreground value enclosed by two at-signs,
+// such as @1F@. The other @ conventions of the early revisions are not rendered,
+// so those sequences and the at-signs of prose and email addresses, such as "@B@",
+// "@1F" or "sysop@bbs.com", pass through as literal text.
+//
+// # Encoding
+//
+// The color codes are all ASCII, except for the WWIV heart code, so the functions work on bytes
+// and accept either the raw CP-437 bytes of a file or the text already decoded to UTF-8.
+// The WWIV heart code is recognized as both the raw ETX (0x03) control and the decoded ♥ glyph,
+// and the [WithHeart] option reads the exports that use another introducer, such as 0x04.
+// The rendered HTML contains the content as given, so the text should be decoded to UTF-8 before
+// it is rendered, for example using the golang.org/x/text/encoding/charmap CodePage437 decoder,
+// or the [WithCodepage] option that decodes the source of the renderers.
+// There are no rune based functions, text from an io.RuneReader can be written to
+// a bytes.Buffer using WriteRune and then used as UTF-8 bytes.
+//
+// # Concurrency
+//
+// All the functions and methods are safe for concurrent use by multiple goroutines.
+// The options are applied to a configuration that is created for each call,
+// and the compiled regular expressions that are shared between the calls are only read.
+// The exported [CGAPalette], [ColorNames] and [CelerityColors] variables are also shared,
+// so they must not be modified while the package is in use.
 //
 // [Bulletin Board Systems]: https://spectrum.ieee.org/social-medias-dialup-ancestor-the-bulletin-board-system
 // [ANSI control codes]: https://www.cse.psu.edu/~kxc104/class/cse472/09f/hw/hw7/vt100ansi.htm
@@ -67,9 +104,13 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
+	"path"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/bengarrett/bbs/internal/split"
 )
@@ -91,13 +132,13 @@ var static embed.FS
 
 // Regular expressions to match BBS color codes.
 const (
-	CelerityRe  string = `\|(k|b|g|c|r|m|y|w|d|B|G|C|R|M|Y|W|S)` // matches Celerity
-	PCBoardRe   string = "(?i)@X([0-9A-F][0-9A-F])"              // matches PCBoard
-	RenegadeRe  string = `\|(0[0-9]|1[1-9]|2[0-3])`              // matches Renegade
-	TelegardRe  string = "(?i)`([0-9|A-F])([0-9|A-F])"           // matches Telegard
-	WildcatRe   string = `(?i)@([0-9|A-F])([0-9|A-F])@`          // matches Wildcat!
-	WWIVHashRe  string = `\|#(\d)`                               // matches WWIV with hashes #
-	WWIVHeartRe string = `\x03(\d)`                              // matches WWIV with hearts ♥
+	CelerityRe  string = `\|(k|b|g|c|r|m|y|w|d|B|G|C|R|M|Y|W|S|!)` // matches Celerity
+	PCBoardRe   string = "(?i)@X([0-9A-F][0-9A-F])"                // matches PCBoard
+	RenegadeRe  string = `\|(0[0-9]|1[0-9]|2[0-3])`                // matches Renegade
+	TelegardRe  string = "(?i)`([0-9A-F])([0-9A-F])"               // matches Telegard
+	WildcatRe   string = `@([0-9A-F])([0-9A-F])@`                  // matches Wildcat!
+	WWIVHashRe  string = `\|#(\d)`                                 // matches WWIV with hashes #
+	WWIVHeartRe string = `(?:\x03|♥)(\d)`                          // matches WWIV with hearts ♥
 )
 
 // Clear is a PCBoard specific control to clear the screen that's occasionally found in ANSI text.
@@ -105,8 +146,15 @@ const (
 	Clear string = "@CLS@"
 
 	celerityCodes = "kbgcrmywdBGCRMYWS"
+	heart         = "♥"            // heart is the decoded CP-437 ETX character.
+	bom           = "\xef\xbb\xbf" // bom is the UTF-8 byte order mark used by some Windows editors.
 )
 
+// trimBOM removes the UTF-8 byte order mark from the start of src.
+func trimBOM(src []byte) []byte {
+	return bytes.TrimPrefix(src, []byte(bom))
+}
+
 // CelerityHTML writes to buf the HTML equivalent of Celerity BBS color codes with
 // matching CSS color classes.
 func CelerityHTML(buf *bytes.Buffer, src ...byte) error {
@@ -122,9 +170,12 @@ func RenegadeHTML(buf *bytes.Buffer, src ...byte) error {
 // WildcatHTML writes to buf the HTML equivalent of Wildcat! BBS color codes with
 // matching CSS color classes.
 func WildcatHTML(buf *bytes.Buffer, src ...byte) error {
-	re := regexp.MustCompile(WildcatRe)
-	p := re.ReplaceAll(src, []byte(`@X$1$2`))
-	return split.PCBoardHTML(buf, p)
+	return split.PCBoardHTML(buf, wildcat(src))
+}
+
+// wildcat replaces the Wildcat! BBS color codes with PCBoard codes.
+func wildcat(src []byte) []byte {
+	return Wildcat.Regexp().ReplaceAll(src, []byte(`@X$1$2`))
 }
 
 // IsCelerity reports if the bytes contains Celerity BBS color codes.
@@ -204,12 +255,18 @@ func IsWWIVHash(b []byte) bool {
 // The format uses the ETX (end-of-text) character as a prefix with a numeric value between 0 and 9.
 //
 // In the MS-DOS era, the common North American [CP-437 codepage] substituted the ETX character with a heart symbol.
+// So text that is already decoded to UTF-8 can use the heart (♥) rune as the prefix, which is also detected.
 //
 // [CP-437 codepage]: https://en.wikipedia.org/wiki/Code_page_437
 func IsWWIVHeart(b []byte) bool {
 	const first, last = 0, 9
 	for i := first; i <= last; i++ {
-		subslice := append(WWIVHeart.Bytes(), []byte(strconv.Itoa(i))...)
+		n := []byte(strconv.Itoa(i))
+		subslice := append(WWIVHeart.Bytes(), n...)
+		if bytes.Contains(b, subslice) {
+			return true
+		}
+		subslice = append([]byte(heart), n...)
 		if bytes.Contains(b, subslice) {
 			return true
 		}
@@ -220,18 +277,54 @@ func IsWWIVHeart(b []byte) bool {
 // IsWildcat reports if the bytes contains Wildcat! BBS color codes.
 // The format uses an a background and foreground,
 // 4-bit hexadecimal color value enclosed with two at-sign (@) characters.
+// The hexadecimal values must be uppercase, so the at-sign use in prose
+// and email addresses, such as a@0b@c, is not mistaken for a color code.
 func IsWildcat(b []byte) bool {
-	const first, last = 0, 15
-	for bg := first; bg <= last; bg++ {
-		for fg := first; fg <= last; fg++ {
-			subslice := []byte(fmt.Sprintf("%s%X%X%s",
-				Wildcat.Bytes(), bg, fg, Wildcat.Bytes()))
-			if bytes.Contains(b, subslice) {
-				return true
-			}
+	return Wildcat.Regexp().Match(b)
+}
+
+// IsText reports if the bytes look like human-readable BBS or ANSI text rather than a binary file,
+// such as a ZIP archive or an image that happens to contain @ or | characters.
+//
+// Up to the first 8 KiB of the bytes are sampled, and at least 95% of the sample must be printable.
+// The CP-437 high bytes used by box-drawing and block characters count as printable,
+// as do the tab, newline, carriage return, form feed, escape, end-of-file and WWIV heart controls.
+func IsText(b []byte) bool {
+	const sample, ratio = 8192, 0.95
+	if len(b) == 0 {
+		return false
+	}
+	if len(b) > sample {
+		b = b[:sample]
+	}
+	printable := 0
+	for _, c := range b {
+		if isPrintable(c) {
+			printable++
 		}
 	}
-	return false
+	return float64(printable)/float64(len(b)) >= ratio
+}
+
+// isPrintable reports whether the CP-437 byte is a printable character or a common text control.
+func isPrintable(c byte) bool {
+	const (
+		etx = 0x03 // WWIV heart
+		tab = 0x09
+		lf  = 0x0a
+		ff  = 0x0c
+		cr  = 0x0d
+		sub = 0x1a // MS-DOS end-of-file
+		esc = 0x1b
+		del = 0x7f
+	)
+	switch c {
+	case etx, tab, lf, ff, cr, sub, esc:
+		return true
+	case del:
+		return false
+	}
+	return c >= ' '
 }
 
 // PCBoardHTML writes to buf the HTML equivalent of PCBoard BBS color codes with
@@ -240,36 +333,98 @@ func PCBoardHTML(buf *bytes.Buffer, src ...byte) error {
 	return split.PCBoardHTML(buf, src)
 }
 
+// PCBoardANSIHTML writes to buf the HTML equivalent of a mix of PCBoard BBS color codes
+// and ANSI color sequences with matching PCBoard CSS color classes.
+// The PCBoard codes and the ANSI sequences share the same color state,
+// so the interpretation can switch per occurrence within a single document.
+// Other ANSI sequences, such as music and cursor movements, are removed.
+func PCBoardANSIHTML(buf *bytes.Buffer, src ...byte) error {
+	return split.PCBoardANSIHTML(buf, src)
+}
+
 // TelegardHTML writes to buf the HTML equivalent of Telegard BBS color codes with
 // matching CSS color classes.
 func TelegardHTML(buf *bytes.Buffer, src ...byte) error {
-	re := regexp.MustCompile(TelegardRe)
-	p := re.ReplaceAll(src, []byte(`@X$1$2`))
-	return split.PCBoardHTML(buf, p)
+	return split.PCBoardHTML(buf, telegard(src))
+}
+
+// telegard replaces the Telegard BBS color codes with PCBoard codes.
+func telegard(src []byte) []byte {
+	return Telegard.Regexp().ReplaceAll(src, []byte(`@X$1$2`))
 }
 
+// controlsRe matches the PCBoard clear screen and pause controls.
+var controlsRe = regexp.MustCompile(`@(CLS|CLS |PAUSE)@`)
+
+// formFeed is the form feed control character that some BBS files use as a screen break.
+const formFeed = '\f'
+
 // TrimControls removes common PCBoard BBS controls prefixes from the bytes.
 // It trims the @CLS@ prefix used to clear the screen and the @PAUSE@ prefix
-// used to pause the display render.
+// used to pause the display render. The form feed (0x0C) screen breaks are also removed.
 func TrimControls(src ...byte) []byte {
-	re := regexp.MustCompile(`@(CLS|CLS |PAUSE)@`)
-	return re.ReplaceAll(src, []byte(""))
+	return bytes.ReplaceAll(trimMacros(src), []byte{formFeed}, []byte(""))
+}
+
+// trimMacros removes the @CLS@ and @PAUSE@ controls from src.
+func trimMacros(src []byte) []byte {
+	return controlsRe.ReplaceAll(src, []byte(""))
+}
+
+// NormalizeNewlines replaces the CRLF and the lone CR line endings in src with LF newlines.
+// None of the BBS color codes contain carriage returns, so the codes are never altered.
+func NormalizeNewlines(src ...byte) []byte {
+	const cr, lf = "\r", "\n"
+	p := bytes.ReplaceAll(src, []byte(cr+lf), []byte(lf))
+	return bytes.ReplaceAll(p, []byte(cr), []byte(lf))
+}
+
+// StripANSIControls removes the ANSI sequences that should never be displayed from src.
+// These include the ANSI music sequences, such as ESC[MF T120 O3 C D E followed by
+// the shift out (0x0E) terminator, cursor movements and saves and restores,
+// and other escape sequences. The ANSI select graphic rendition color sequences are kept.
+func StripANSIControls(src ...byte) []byte {
+	return split.StripANSIControls(src)
 }
 
 // WWIVHashHTML writes to buf the HTML equivalent of WWIV BBS hash (#) color codes with
-// matching CSS color classes.
+// matching CSS color classes. The codes use a single digit, so |#12 is the color 1 followed by 2.
 func WWIVHashHTML(buf *bytes.Buffer, src ...byte) error {
-	re := regexp.MustCompile(WWIVHashRe)
-	p := re.ReplaceAll(src, []byte(`|0$1`))
-	return split.VBarsHTML(buf, p)
+	return split.VBarsHTML(buf, wwivHash(src))
+}
+
+// wwivHash replaces the WWIV BBS hash (#) color codes with Renegade codes.
+func wwivHash(src []byte) []byte {
+	return WWIVHash.Regexp().ReplaceAll(src, []byte(`|0$1`))
 }
 
 // WWIVHeartHTML writes to buf the HTML equivalent of WWIV BBS heart (♥) color codes with
 // matching CSS color classes.
 func WWIVHeartHTML(buf *bytes.Buffer, src ...byte) error {
-	re := regexp.MustCompile(WWIVHeartRe)
-	p := re.ReplaceAll(src, []byte(`|0$1`))
-	return split.VBarsHTML(buf, p)
+	return split.VBarsHTML(buf, wwivHeart(src))
+}
+
+// wwivHeart replaces the WWIV BBS heart (♥) color codes with Renegade codes.
+func wwivHeart(src []byte) []byte {
+	return WWIVHeart.Regexp().ReplaceAll(src, []byte(`|0$1`))
+}
+
+// wwivRe matches both the WWIV BBS hash (#) and heart (♥) color codes.
+var wwivRe = regexp.MustCompile(`(?:\|#|\x03|♥)(\d)`)
+
+// ConvertWWIV replaces both the WWIV BBS hash (#) and heart (♥) color codes in src
+// with the color codes of the to format, which must be WWIVHash, WWIVHeart or Renegade.
+// The two WWIV formats use the same palette, so archives that mix them can be normalized.
+// The heart codes are written using the CP-437 ETX (0x03) character, see [BBS.Bytes].
+// Any other format returns src unchanged.
+func ConvertWWIV(src []byte, to BBS) []byte {
+	switch to {
+	case WWIVHash, WWIVHeart:
+		return wwivRe.ReplaceAll(src, append(to.Bytes(), "$1"...))
+	case Renegade:
+		return wwivRe.ReplaceAll(src, []byte(`|0$1`))
+	}
+	return src
 }
 
 // A BBS (Bulletin Board System) color code format,
@@ -289,87 +444,409 @@ const (
 )
 
 // Fields splits the io.Reader around the first instance of one or more consecutive BBS color codes.
+// Like the HTML renderers, the color codes at the end of the reader that have no content are dropped.
 // An error is returned if no color codes are found or if ANSI control sequences are first found.
-func Fields(src io.Reader) ([]string, BBS, error) {
-	buf := bytes.Buffer{}
-	r := io.TeeReader(src, &buf)
-	f := Find(r)
-	if !f.Valid() {
-		return nil, -1, ErrNone
+func Fields(src io.Reader, opts ...Option) ([]string, BBS, error) {
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, -1, err
+	}
+	src, err := cfg.gunzip(src)
+	if err != nil {
+		return nil, -1, err
 	}
-	b, err := io.ReadAll(&buf)
+	scratch := cfg.scratch()
+	defer cfg.release(scratch)
+	f, b, err := cfg.findAll(src, scratch)
 	if err != nil {
 		return nil, -1, err
 	}
+	if !f.Valid() {
+		return nil, -1, ErrNone
+	}
 	switch f {
 	case ANSI:
 		return nil, -1, ErrANSI
 	case Celerity:
-		return split.Celerity(b), f, nil
+		return trailing(split.Celerity(b), b, Celerity, 1), f, nil
 	case PCBoard, Telegard, Wildcat:
-		return split.PCBoard(b), f, nil
+		return trailing(split.PCBoard(b), b, PCBoard, 2), f, nil
 	case Renegade, WWIVHash, WWIVHeart:
-		return split.VBars(b), f, nil
+		return trailing(split.VBars(b), b, Renegade, 2), f, nil
 	}
 	return nil, -1, ErrNone
 }
 
+// trailing returns the fields of src without the color codes at the end that have no content,
+// which are the fields of no more than the n bytes of the color value. The codes are matched by
+// the regular expression of the split format, and when src has text before the first code,
+// the first field is that text and it is never dropped.
+func trailing(fields []string, src []byte, format BBS, n int) []string {
+	keep := 0
+	if loc := regexps[format].FindIndex(src); loc != nil && loc[0] > 0 {
+		keep = 1
+	}
+	for len(fields) > keep && len(fields[len(fields)-1]) <= n {
+		fields = fields[:len(fields)-1]
+	}
+	return fields
+}
+
 // Find the format of any known BBS color code sequence within the reader.
 // If no sequences are found -1 is returned.
+// The reader can be the raw CP-437 bytes or the decoded UTF-8 text, see the Encoding section.
+//
+// Find is a detection-only, streaming scan that returns after the first line containing a
+// color code, so only the beginning of a large reader is read and nothing else is buffered.
+// The lines can end with LF, CRLF or CR, and the text without line endings is scanned
+// in overlapping chunks, so there is no limit to the length of a line.
+// A UTF-8 byte order mark at the start of the reader is ignored.
+//
+// When a line contains the color codes of more than one format, such as the Renegade |07 codes with
+// the PCBoard @X codes of prose, the format with the most valid codes in the line is found.
+// The formats with the same number of codes are chosen in the order of detection, which by default is
+// ANSI, Renegade, Celerity, PCBoard, Telegard, Wildcat!, WWIV hash and WWIV heart,
+// followed by the formats added with [Register], see [SetDetectionOrder].
+//
+// When the reader is an io.ReadSeeker with a SAUCE record that declares a PCBoard or ANSi file type,
+// see [SAUCE.BBS], the declared format is preferred over the other formats found in the same line.
+// The reader is returned to its current offset before the content is scanned.
 func Find(r io.Reader) BBS {
+	declared := BBS(-1)
+	if rs, ok := r.(io.ReadSeeker); ok {
+		declared = sauceFormat(rs)
+	}
+	d := newDetector(declared)
 	scanner := bufio.NewScanner(r)
+	scanner.Split(scanLines())
 	for scanner.Scan() {
-		b := scanner.Bytes()
-		p := bytes.TrimSpace(b)
-		if p == nil {
+		if f := d.line(scanner.Bytes()); f != -1 {
+			return f
+		}
+	}
+	return -1
+}
+
+// findBytes returns the format of src, that is found the same as [Find] without reading
+// the SAUCE record, but from the bytes in memory without the line scanner and its buffer.
+func findBytes(src []byte) BBS {
+	d := newDetector(-1)
+	split := scanLines()
+	for len(src) > 0 {
+		n, line, _ := split(src, true)
+		if f := d.line(line); f != -1 {
+			return f
+		}
+		src = src[n:]
+	}
+	return -1
+}
+
+// detector finds the format of the lines of a text, see [Find].
+type detector struct {
+	declared BBS   // declared is the format of the SAUCE record, or -1.
+	formats  []BBS // formats are the formats in the order of detection.
+	custom   bool  // custom reports whether there are registered formats.
+	first    bool  // first reports whether the next line is the first line.
+}
+
+// newDetector returns a detector of the formats in the order of detection.
+func newDetector(declared BBS) *detector {
+	formats, custom := detection()
+	return &detector{declared: declared, formats: formats, custom: custom, first: true}
+}
+
+// line returns the format found in the next line of the text, or -1 if no format is found.
+func (d *detector) line(b []byte) BBS {
+	if d.first {
+		b, d.first = trimBOM(b), false
+	}
+	p := bytes.TrimSpace(b)
+	if p == nil {
+		return -1
+	}
+	builtin := bytes.ContainsAny(b, introducers)
+	if !builtin && !d.custom {
+		return -1
+	}
+	const l = len(Clear)
+	if len(p) > l {
+		if bytes.Equal(p[0:l], []byte(Clear)) {
+			b = p[l:]
+		}
+	}
+	if d.declared.Valid() && d.declared.Regexp().Match(b) {
+		return d.declared
+	}
+	return weigh(d.formats, b, builtin)
+}
+
+// weigh returns the detected format of the line with the most valid color codes,
+// or -1 if no format is detected. The formats with the same number of codes are
+// chosen in the order of the formats, and the registered formats count as a single code.
+// ANSI is not weighed, it is found when no format precedes it in the order.
+// The builtin formats are only detected when the line has their introducers.
+func weigh(formats []BBS, line []byte, builtin bool) BBS {
+	find, most := BBS(-1), 0
+	for _, f := range formats {
+		if !(builtin || f >= firstCustom) || !f.Detect(line) {
 			continue
 		}
-		const l = len(Clear)
-		if len(p) > l {
-			if bytes.Equal(p[0:l], []byte(Clear)) {
-				b = p[l:]
+		if f == ANSI {
+			// the escape sequences are never prose, so they are not weighed
+			if find == -1 {
+				return ANSI
 			}
+			continue
 		}
-		switch {
-		case bytes.Contains(b, ANSI.Bytes()):
-			return ANSI
-		case bytes.Contains(b, Celerity.Bytes()):
-			if IsRenegade(b) {
-				return Renegade
-			}
-			if IsCelerity(b) {
-				return Celerity
-			}
-			return -1
-		case IsPCBoard(b):
-			return PCBoard
-		case IsTelegard(b):
-			return Telegard
-		case IsWildcat(b):
-			return Wildcat
-		case IsWWIVHash(b):
-			return WWIVHash
-		case IsWWIVHeart(b):
-			return WWIVHeart
+		n := 1
+		if re := f.Regexp(); re != nil {
+			n = len(re.FindAllIndex(line, -1))
+		}
+		if n > most {
+			find, most = f, n
 		}
 	}
-	return -1
+	return find
+}
+
+// FindSample finds the format of any known BBS color code sequence within the first n bytes of the reader.
+// It trades completeness for speed on large files, as most files use a color code in the opening lines,
+// but a file that only uses color codes after the sample, or a code that is cut by the end of the sample,
+// is missed and returns -1. A sample size of 0 or less returns -1.
+func FindSample(r io.Reader, n int) BBS {
+	if n <= 0 {
+		return -1
+	}
+	return Find(io.LimitReader(r, int64(n)))
+}
+
+// introducers are the first characters of all the built-in color codes,
+// so the lines without these characters are skipped by Find, unless a custom format is registered.
+const introducers = "\x03\x1b@`|" + heart
+
+// Chunk sizes of the text without line endings that is scanned by Find.
+const (
+	chunk   = 4096 // chunk is the maximum length of a scanned line.
+	overlap = 8    // overlap is the number of bytes shared by the chunks, which is longer than any code.
+)
+
+// scanLines returns a bufio.SplitFunc that returns each line of text without the line ending.
+// A LF, a CR, or a CRLF, that returns an empty line, are the line endings.
+// A line that is longer than the chunk size is returned as overlapping chunks,
+// so a color code that crosses the end of a chunk is found in the next chunk.
+//
+// The split function remembers the bytes already searched for a line ending, so a reader
+// that returns a few bytes at a time is not searched again from the start of each line.
+func scanLines() bufio.SplitFunc {
+	searched := 0
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.IndexAny(data[min(searched, len(data)):], "\r\n"); i >= 0 && searched+i < chunk {
+			i += searched
+			searched = 0
+			return i + 1, data[:i], nil
+		}
+		if len(data) >= chunk {
+			searched = 0
+			return chunk - overlap, data[:chunk], nil
+		}
+		if atEOF {
+			searched = 0
+			return len(data), data, nil
+		}
+		searched = len(data)
+		return 0, nil, nil
+	}
+}
+
+// FindScored finds the format of any known BBS color code sequence within the reader,
+// and returns a confidence score between 0 and 1 of the result.
+// Like [Find] it returns after the first line containing a color code,
+// so the score is based on the sample of text read up to that point.
+//
+// The score increases with the number of valid codes in the sample, so a tiny sample with
+// a single code has a low score. It is reduced when the codes are ambiguous, such as the
+// Renegade |07 which is also a WWIV pipe code, or when other formats also match the sample.
+// A format added with [Register] has no regular expression, so like the weighing of [Find],
+// its sample counts as a single code and the other formats match the sample using [BBS.Detect].
+// If no sequences are found -1 and 0 are returned.
+func FindScored(r io.Reader) (BBS, float64) {
+	buf := bytes.Buffer{}
+	find := Find(io.TeeReader(r, &buf))
+	if find == -1 {
+		return -1, 0
+	}
+	p := buf.Bytes()
+	n := 1.0
+	if re := find.Regexp(); re != nil {
+		n = float64(len(re.FindAllIndex(p, -1)))
+	}
+	score := n / (n + 1)
+	ambiguous := 0
+	if find == Renegade {
+		ambiguous++ // WWIV also uses the Renegade pipe codes
+	}
+	formats, _ := detection()
+	for _, b := range formats {
+		if b == find || b == ANSI {
+			continue
+		}
+		match := b.Detect
+		if re := b.Regexp(); re != nil {
+			match = re.Match
+		}
+		if match(p) {
+			ambiguous++
+		}
+	}
+	return find, score / float64(1+ambiguous)
+}
+
+// regexps are the compiled regular expressions of the BBS formats, indexed by BBS.
+var regexps = [...]*regexp.Regexp{
+	ANSI:      regexp.MustCompile(ansiRe),
+	Celerity:  regexp.MustCompile(CelerityRe),
+	PCBoard:   regexp.MustCompile(PCBoardRe),
+	Renegade:  regexp.MustCompile(RenegadeRe),
+	Telegard:  regexp.MustCompile(TelegardRe),
+	Wildcat:   regexp.MustCompile(WildcatRe),
+	WWIVHash:  regexp.MustCompile(WWIVHashRe),
+	WWIVHeart: regexp.MustCompile(WWIVHeartRe),
+}
+
+// Regexp returns the compiled regular expression that matches the color codes of the BBS format.
+// ANSI returns an expression that matches the ANSI control sequence introducer (CSI) sequences.
+// An invalid BBS returns nil.
+//
+// The expression is compiled once and shared, it is safe for concurrent use
+// but it must not be modified, such as with the Longest method.
+func (b BBS) Regexp() *regexp.Regexp {
+	if !b.Valid() {
+		return nil
+	}
+	return regexps[b]
 }
 
 // HTML writes to buf the HTML equivalent of BBS color codes with matching CSS color classes.
-// The first found color code format is used for the remainder of the Reader.
-func HTML(buf *bytes.Buffer, src io.Reader) (BBS, error) {
+// The first found color code format is used for the remainder of the Reader,
+// except for documents that mix PCBoard codes with ANSI sequences,
+// which are rendered using [PCBoardANSIHTML].
+// On error, nothing is written to buf, see [BBS.HTML].
+func HTML(buf *bytes.Buffer, src io.Reader, opts ...Option) (BBS, error) {
 	if buf == nil {
 		return -1, ErrBuff
 	}
-	w := bytes.Buffer{}
-	r := io.TeeReader(src, &w)
-	find := Find(r)
-	p, err := io.ReadAll(&w)
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return -1, err
+	}
+	src, err := cfg.gunzip(src)
+	if err != nil {
+		return -1, err
+	}
+	scratch := cfg.scratch()
+	defer cfg.release(scratch)
+	find, p, err := cfg.findAll(src, scratch)
 	if err != nil {
 		return -1, err
 	}
-	return find, find.HTML(buf, p)
+	if mixed(find, p) {
+		c := cfg.split()
+		c.Remap = cfg.remap(PCBoard)
+		c.Title = cfg.title(PCBoard)
+		c.Code = cfg.codeLabel(PCBoard)
+		c.Ice = cfg.ice(p)
+		if p, err = cfg.decode(trimBOM(p)); err != nil {
+			return find, err
+		}
+		p = NormalizeNewlines(TrimControls(p...)...)
+		if !cfg.xhtml {
+			return find, c.PCBoardANSIHTML(buf, p)
+		}
+		w := bytes.Buffer{}
+		if err := c.PCBoardANSIHTML(&w, p); err != nil {
+			return find, err
+		}
+		_, err := buf.Write(cfg.finish(w.Bytes()))
+		return find, err
+	}
+	return find, find.HTML(buf, p, opts...)
+}
+
+// findAll returns the format found in src, and all the bytes read from src up to the maximum size.
+// The bytes are read once into the scratch buffer, so they are only valid until the buffer is reused,
+// and the format is found in the bytes, see [Find], rather than in a second copy of the reader.
+// When no format is found, the [WithHeart] introducers are replaced within the scratch buffer.
+func (c config) findAll(src io.Reader, scratch *bytes.Buffer) (BBS, []byte, error) {
+	limit := c.maxSize
+	if limit > 0 {
+		src = io.LimitReader(src, limit+1)
+	}
+	p, err := readAll(src, limit, scratch)
+	if err != nil {
+		return -1, nil, err
+	}
+	find := declare(findBytes(p), p)
+	if find == -1 && c.replaceHearts(p) {
+		find = WWIVHeart
+	}
+	return find, p, nil
+}
+
+// declare returns the BBS format declared by the SAUCE record of src when the content
+// contains the codes of the declared format, otherwise the found format is returned.
+func declare(find BBS, src []byte) BBS {
+	s, ok := ParseSAUCE(src)
+	if !ok {
+		return find
+	}
+	if b := s.BBS(); b.Valid() && b.Regexp().Match(TrimSAUCE(src)) {
+		return b
+	}
+	return find
+}
+
+// readAll reads from r until EOF, appends the data to the scratch buffer and returns its bytes,
+// or an ErrSize error if the bytes are larger than the limit.
+func readAll(r io.Reader, limit int64, scratch *bytes.Buffer) ([]byte, error) {
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1-int64(scratch.Len()))
+	}
+	if _, err := scratch.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(scratch.Len()) > limit {
+		return nil, fmt.Errorf("%w: %d bytes", ErrSize, limit)
+	}
+	return scratch.Bytes(), nil
+}
+
+// mixed reports whether the PCBoard or ANSI src contains both PCBoard codes and ANSI sequences.
+func mixed(find BBS, src []byte) bool {
+	if find != PCBoard && find != ANSI {
+		return false
+	}
+	return IsPCBoard(src) && bytes.Contains(src, ANSI.Bytes())
+}
+
+// CodeLen returns the length in bytes of a complete color code of the fixed length formats,
+// for example 4 for the PCBoard @X07, so a scanner can advance past a code without the regular expression.
+// The WWIV heart code uses the raw ETX control, the decoded ♥ glyph adds two more bytes.
+// The variable length ANSI sequences and an invalid BBS return 0.
+func (b BBS) CodeLen() int {
+	switch b {
+	case Celerity, WWIVHeart:
+		return 2 // |w and ETX 7
+	case Renegade, Telegard, WWIVHash:
+		return 3 // |07, `07 and |#7
+	case PCBoard, Wildcat:
+		return 4 // @X07 and @07@
+	default:
+		return 0
+	}
 }
 
 // Bytes returns the BBS color toggle sequence.
@@ -423,36 +900,162 @@ func (b BBS) CSS(buf *bytes.Buffer) error {
 	return nil
 }
 
+// CSSInline writes to buf the Cascading Style Sheets classes needed by the HTML,
+// as a single, self-contained stylesheet. Unlike [BBS.CSS], the @import stylesheets
+// are resolved and included, so the text_bbs.css and text_blink.css files do not need hosting.
+func (b BBS) CSSInline(buf *bytes.Buffer) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	return inline(buf, "text_pcboard.css", map[string]bool{})
+}
+
+// importRe matches the @import url rules of the embedded stylesheets.
+var importRe = regexp.MustCompile(`@import url\("([^"]+)"\);\n?`)
+
+// inline writes to buf the named embedded stylesheet with the @import rules replaced
+// by the imported stylesheets. The seen stylesheets are only included once.
+func inline(buf *bytes.Buffer, name string, seen map[string]bool) error {
+	if seen[name] {
+		return nil
+	}
+	seen[name] = true
+	p, err := static.ReadFile(path.Join("static/css", name))
+	if err != nil {
+		return err
+	}
+	last := 0
+	for _, m := range importRe.FindAllSubmatchIndex(p, -1) {
+		buf.Write(p[last:m[0]])
+		if err := inline(buf, string(p[m[2]:m[3]]), seen); err != nil {
+			return err
+		}
+		last = m[1]
+	}
+	buf.Write(p[last:])
+	if !bytes.HasSuffix(p, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
 // HTML writes to buf the BBS color codes as CSS color classes within HTML <i> elements.
-func (b BBS) HTML(buf *bytes.Buffer, src []byte) error {
+// Any CRLF or CR line endings are normalized to LF newlines, and the form feed screen breaks
+// are removed unless the [WithPageBreak] option is used. A leading UTF-8 byte order mark is removed.
+// The color codes at the end of src that have no content, such as the reset of a file
+// that ends with @X07, are dropped, so there are no trailing empty elements.
+//
+// On error, nothing is written to buf, so it never contains a partial or an unclosed element.
+func (b BBS) HTML(buf *bytes.Buffer, src []byte, opts ...Option) error {
 	if buf == nil {
 		return ErrBuff
 	}
-	p := TrimControls(src...)
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	if f := b.custom(); f != nil {
+		return f.HTML(buf, src, opts...)
+	}
+	if err := cfg.strict(b, src); err != nil {
+		return err
+	}
+	c := cfg.split()
+	if b != PCBoard {
+		c.Reset = false
+	}
+	c.Remap = cfg.remap(b)
+	c.Title = cfg.title(b)
+	c.Code = cfg.codeLabel(b)
+	c.Ice = cfg.ice(src)
+	if b == WWIVHeart {
+		src = cfg.hearts(src)
+	}
+	src, err := cfg.decode(trimBOM(src))
+	if err != nil {
+		return err
+	}
+	if !cfg.pageBreak && !cfg.xhtml {
+		return b.render(buf, NormalizeNewlines(TrimControls(src...)...), c)
+	}
+	p := NormalizeNewlines(TrimControls(src...)...)
+	if cfg.pageBreak {
+		// the form feeds pass through the renderers and are then replaced by the page breaks
+		p = NormalizeNewlines(trimMacros(src)...)
+	}
+	w := bytes.Buffer{}
+	if err := b.render(&w, p, c); err != nil {
+		return err
+	}
+	_, err = buf.Write(cfg.finish(w.Bytes()))
+	return err
+}
+
+// finish returns the rendered HTML with the form feeds replaced by the page breaks,
+// and when using XHTML, without the control characters that XML does not allow.
+func (c config) finish(html []byte) []byte {
+	if c.pageBreak {
+		pageBreak := `<br class="` + c.prefix + `page">`
+		if c.xhtml {
+			pageBreak = `<br class="` + c.prefix + `page" />`
+		}
+		html = bytes.ReplaceAll(html, []byte{formFeed}, []byte(pageBreak))
+	}
+	if !c.xhtml {
+		return html
+	}
+	return bytes.Map(func(r rune) rune {
+		if r < ' ' && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, html)
+}
+
+// render writes to buf the HTML of the BBS color codes in p using the configuration.
+func (b BBS) render(buf *bytes.Buffer, p []byte, c split.Config) error {
 	switch b {
 	case ANSI:
 		return ErrANSI
 	case Celerity:
-		return CelerityHTML(buf, p...)
+		return c.CelerityHTML(buf, p)
 	case PCBoard:
-		return PCBoardHTML(buf, p...)
+		return c.PCBoardHTML(buf, p)
 	case Renegade:
-		return RenegadeHTML(buf, p...)
+		return c.VBarsHTML(buf, p)
 	case Telegard:
-		return TelegardHTML(buf, p...)
+		return c.PCBoardHTML(buf, telegard(p))
 	case Wildcat:
-		return WildcatHTML(buf, p...)
+		return c.PCBoardHTML(buf, wildcat(p))
 	case WWIVHash:
-		return WWIVHashHTML(buf, p...)
+		return c.VBarsHTML(buf, wwivHash(p))
 	case WWIVHeart:
-		return WWIVHeartHTML(buf, p...)
+		return c.VBarsHTML(buf, wwivHeart(p))
 	default:
 		return ErrNone
 	}
 }
 
+// TemplateHTML returns the BBS color codes as CSS color classes within HTML <i> elements,
+// as a [template.HTML] value that is not escaped again when used by the html/template package.
+//
+// Safety contract: the content of src is always HTML escaped by the renderer, and
+// the only markup in the result are the <i> elements and class attributes created
+// by this package. So the result is safe to embed in a HTML template, even when
+// src is untrusted user input. The contract does not apply when using the [WithUnsafeNoEscape] option.
+func (b BBS) TemplateHTML(src []byte, opts ...Option) (template.HTML, error) {
+	buf := bytes.Buffer{}
+	if err := b.HTML(&buf, src, opts...); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
 // Name returns the name of the BBS color format.
 func (b BBS) Name() string {
+	if f := b.custom(); f != nil {
+		return f.Name()
+	}
 	if !b.Valid() {
 		return ""
 	}
@@ -468,44 +1071,139 @@ func (b BBS) Name() string {
 	}[b]
 }
 
+// Slug returns the lowercase identifier of the BBS color format, such as "pcboard" or "wwiv-hash",
+// that is safe to use in the URLs, the file names, the CSS scopes and as a configuration key.
+// Unlike [BBS.Name], which is for display, a slug only contains the ASCII letters and digits
+// separated by hyphens. The slug of a registered format is made from its name, so "My Board!"
+// is "my-board". An invalid BBS returns an empty string.
+func (b BBS) Slug() string {
+	if f := b.custom(); f != nil {
+		return slug(f.Name())
+	}
+	if !b.Valid() {
+		return ""
+	}
+	return [...]string{
+		"ansi",
+		"celerity",
+		"pcboard",
+		"renegade",
+		"telegard",
+		"wildcat",
+		"wwiv-hash",
+		"wwiv-heart",
+	}[b]
+}
+
+// slug returns the lowercase ASCII letters and digits of the name, with the other characters
+// between them replaced by a single hyphen.
+func slug(name string) string {
+	sb := strings.Builder{}
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return sb.String()
+}
+
 // Remove the BBS color codes from src and write it to buf.
+// Only the color codes are removed, all other bytes are kept as-is, including any whitespace
+// and newlines that precede or follow the last color code, so the result is lossless for the visible text.
+// Unlike the HTML renderers, the @CLS@ and @PAUSE@ controls and the CRLF line endings are also kept.
 func (b BBS) Remove(buf *bytes.Buffer, src ...byte) error {
 	if buf == nil {
 		return ErrBuff
 	}
+	if f := b.custom(); f != nil {
+		return f.Remove(buf, src...)
+	}
 	switch b {
 	case ANSI:
 		return ErrANSI
 	case Celerity:
-		return remove(buf, src, CelerityRe)
+		return remove(buf, src, Celerity.Regexp())
 	case PCBoard:
-		return remove(buf, src, PCBoardRe)
+		return remove(buf, src, PCBoard.Regexp())
 	case Renegade:
-		return remove(buf, src, RenegadeRe)
+		return remove(buf, src, Renegade.Regexp())
 	case Telegard:
-		return remove(buf, src, TelegardRe)
+		return remove(buf, src, Telegard.Regexp())
 	case Wildcat:
-		return remove(buf, src, WildcatRe)
+		return remove(buf, src, Wildcat.Regexp())
 	case WWIVHash:
-		return remove(buf, src, WWIVHashRe)
+		return remove(buf, src, WWIVHash.Regexp())
 	case WWIVHeart:
-		return remove(buf, src, WWIVHeartRe)
+		return remove(buf, src, WWIVHeart.Regexp())
 	}
 	return ErrNone
 }
 
-func remove(buf *bytes.Buffer, src []byte, expr string) error {
+// remove writes src to buf without the matches of re.
+// The buffer is grown once and the text between the matches is written directly,
+// so unlike ReplaceAll there is no intermediate copy of src.
+func remove(buf *bytes.Buffer, src []byte, re *regexp.Regexp) error {
 	if buf == nil {
 		return ErrBuff
 	}
-	re := regexp.MustCompile(expr)
-	p := re.ReplaceAll(src, []byte(""))
-	_, err := buf.Write(p)
-	return err
+	buf.Grow(len(src))
+	for {
+		loc := re.FindIndex(src)
+		if loc == nil {
+			break
+		}
+		buf.Write(src[:loc[0]])
+		src = src[loc[1]:]
+	}
+	buf.Write(src)
+	return nil
+}
+
+// Extensions returns the common file extensions, in lowercase with a leading dot,
+// of the files that use the BBS color format. The PCBoard hello.pcb example uses ".pcb".
+// Formats without a commonly used extension, or an invalid BBS, return nil.
+func (b BBS) Extensions() []string {
+	switch b {
+	case ANSI:
+		return []string{".ans"}
+	case PCBoard:
+		return []string{".pcb"}
+	case Renegade:
+		return []string{".asc"}
+	case Wildcat:
+		return []string{".bbs"}
+	case WWIVHeart:
+		return []string{".msg"}
+	}
+	return nil
+}
+
+// ByExtension returns the likely BBS color format of the file extension, such as ".pcb" or "pcb".
+// The extension is case-insensitive and is a hint to use before the content is detected with [Find].
+// An unknown extension returns -1 and an [ErrNone] error.
+func ByExtension(ext string) (BBS, error) {
+	ext = "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+	for b := ANSI; b.Valid(); b++ {
+		if slices.Contains(b.Extensions(), ext) {
+			return b, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %q extension", ErrNone, ext)
 }
 
 // String returns the BBS color format name and toggle sequence.
+// A registered format, see [Register], returns its name.
 func (b BBS) String() string {
+	if f := b.custom(); f != nil {
+		return f.Name()
+	}
 	if !b.Valid() {
 		return ""
 	}
@@ -521,7 +1219,7 @@ func (b BBS) String() string {
 	}[b]
 }
 
-// Valid reports whether the BBS type is valid.
+// Valid reports whether the BBS type is a valid built-in format.
 func (b BBS) Valid() bool {
 	switch b {
 	case ANSI,
@@ -2,9 +2,18 @@ package bbs_test
 
 import (
 	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/bengarrett/bbs"
 )
@@ -53,6 +62,29 @@ func TestBBS_Name(t *testing.T) {
 	}
 }
 
+func TestBBS_Slug(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		want string
+	}{
+		{"too small", -1, ""},
+		{"too big", 111, ""},
+		{"first", bbs.ANSI, "ansi"},
+		{"pcboard", bbs.PCBoard, "pcboard"},
+		{"wildcat", bbs.Wildcat, "wildcat"},
+		{"hash", bbs.WWIVHash, "wwiv-hash"},
+		{"last", bbs.WWIVHeart, "wwiv-heart"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Slug(); got != tt.want {
+				t.Errorf("BBS.Slug() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFind(t *testing.T) {
 	type args struct {
 		s string
@@ -71,8 +103,16 @@ func TestFind(t *testing.T) {
 		{"renegade", args{"Hello world\n|09This is a newline."}, bbs.Renegade},
 		{"pcboard", args{"Hello world\n@X01This is a newline."}, bbs.PCBoard},
 		{"telegard", args{"Hello world\n`09This is a newline."}, bbs.Telegard},
+		{"telegard bar", args{"Hello world\n`|AThis is a newline."}, -1},
 		{"wildcat", args{"Hello world\n@01@This is a newline."}, bbs.Wildcat},
+		{"at sign prose", args{"Mail sysop@bbs.com @ 5pm\n@ the @B@ and @1F or @AB.com"}, -1},
+		{"wwiv #", args{"Hello world\n|#1This is a newline."}, bbs.WWIVHash},
+		{"pipe prose", args{"Hello | world\n@X01This is a newline."}, bbs.PCBoard},
+		{"renegade with @X prose", args{"|15Type @X1F for the |14PCBoard |07colors"}, bbs.Renegade},
+		{"pcboard with pipe prose", args{"@X0FPress |07 to @X0Econtinue @X07now"}, bbs.PCBoard},
+		{"same count", args{"|07Press @X0F"}, bbs.Renegade},
 		{"wwiv ♥", args{"Hello world\n\x031This is a newline."}, bbs.WWIVHeart},
+		{"wwiv ♥ glyph", args{"Hello world\n♥1This is a newline."}, bbs.WWIVHeart},
 		{"pcboard with nulls", args{"hello\n\n@X01world"}, bbs.PCBoard},
 	}
 	for _, tt := range tests {
@@ -138,6 +178,7 @@ func Test_IsCelerity(t *testing.T) {
 		{"false positive s", args{[]byte("Hello |sworld")}, false},
 		{"cel B", args{[]byte("Hello |Bworld")}, true},
 		{"cel W", args{[]byte("Hello world\n|WThis is a newline.")}, true},
+		{"control only", args{[]byte("Hello |!world")}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -215,6 +256,8 @@ func Test_IsWildcat(t *testing.T) {
 		{"first", args{[]byte("@00@Hello world")}, true},
 		{"end", args{[]byte("@FF@Hello world")}, true},
 		{"newline", args{[]byte("Hello world\n@00@This is a newline.")}, true},
+		{"lowercase", args{[]byte("a@0b@c")}, false},
+		{"email", args{[]byte("Email user@host.com @ 5pm")}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -240,6 +283,9 @@ func Test_IsWWIVHeart(t *testing.T) {
 		{"last", args{[]byte("\x039Hello world")}, true},
 		{"lots of numbers", args{[]byte("\x0398765 Hello world")}, true},
 		{"newline", args{[]byte("Hello world\n\x031This is a newline.")}, true},
+		{"glyph malformed", args{[]byte("♥Hello world")}, false},
+		{"glyph", args{[]byte("♥7Hello world")}, true},
+		{"glyph newline", args{[]byte("Hello world\n♥1This is a newline.")}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -292,6 +338,8 @@ func Test_RenegadeHTML(t *testing.T) {
 		{"string", args{"hello world"}, "hello world", false},
 		{"false pos", args{"hello|world"}, "hello|world", false},
 		{"false pos double", args{"| hello world |"}, "| hello world |", false},
+		{"light green", args{"|10Hello |15world"}, "<i class=\"P0 P10\">Hello </i><i class=\"P0 P15\">world</i>", false},
+		{"out of range", args{"|10Hello |24world"}, "<i class=\"P0 P10\">Hello |24world</i>", false},
 		{"prefix", args{"|" + black + white + "Hello world"}, "<i class=\"P0 P7\">Hello world</i>", false},
 		{
 			"multi",
@@ -303,6 +351,8 @@ func Test_RenegadeHTML(t *testing.T) {
 			args{"|07White\n|20Red Background"},
 			"<i class=\"P0 P7\">White\n</i><i class=\"P20 P7\">Red Background</i>", false,
 		},
+		{"leading", args{"Hello |07world"}, "Hello <i class=\"P0 P7\">world</i>", false},
+		{"leading byte", args{"a|07b"}, "a<i class=\"P0 P7\">b</i>", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -341,6 +391,15 @@ func Test_PCBoardHTML(t *testing.T) {
 		{"false pos 2", args{"PCBoard @Xcode"}, "PCBoard @Xcode", false},
 		{"false pos 3", args{"Does PCBoard @X code offer a red @X?"}, "Does PCBoard @X code offer a red @X?", false},
 		{"combo", args{"@X07@Xcodes combo"}, "<i class=\"PB0 PF7\">@Xcodes combo</i>", false},
+		{"adjacent", args{"@X07@X11Hi"}, "<i class=\"PB0 PF7\"></i><i class=\"PB1 PF1\">Hi</i>", false},
+		{"adjacent three", args{"@X07@X11@X1FHi"},
+			"<i class=\"PB0 PF7\"></i><i class=\"PB1 PF1\"></i><i class=\"PB1 PFF\">Hi</i>", false},
+		{"adjacent incomplete", args{"@X07@X1Hi"}, "<i class=\"PB0 PF7\">@X1Hi</i>", false},
+		{"adjacent out of range", args{"@X07@XG1Hi"}, "<i class=\"PB0 PF7\">@XG1Hi</i>", false},
+		{"adjacent hex content", args{"@X07@X1Fun"}, "<i class=\"PB0 PF7\"></i><i class=\"PB1 PFF\">un</i>", false},
+		{"literal then code", args{"@X@X07Hi"}, "@X<i class=\"PB0 PF7\">Hi</i>", false},
+		{"leading", args{"Hello @X07world"}, "Hello <i class=\"PB0 PF7\">world</i>", false},
+		{"leading byte", args{"a@X07b"}, "a<i class=\"PB0 PF7\">b</i>", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -370,6 +429,8 @@ func Test_TelegardHTML(t *testing.T) {
 		{"empty", args{""}, "", false},
 		{"string", args{"hello world"}, "hello world", false},
 		{"prefix", args{"`07Hello world"}, "<i class=\"PB0 PF7\">Hello world</i>", false},
+		{"lowercase", args{"`1fHello world"}, "<i class=\"PB1 PFF\">Hello world</i>", false},
+		{"vertical bar", args{"`|7Hello `0|world"}, "`|7Hello `0|world", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -399,6 +460,9 @@ func Test_WWIVHashHTML(t *testing.T) {
 		{"empty", args{}, "", false},
 		{"string", args{"hello world"}, "hello world", false},
 		{"prefix", args{"|#7Hello world"}, "<i class=\"P0 P7\">Hello world</i>", false},
+		{"single digit", args{"|#1Hi"}, "<i class=\"P0 P1\">Hi</i>", false},
+		{"two digits", args{"|#12Hi"}, "<i class=\"P0 P1\">2Hi</i>", false},
+		{"adjacent", args{"|#1|#2Hi"}, "<i class=\"P0 P1\"></i><i class=\"P0 P2\">Hi</i>", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -428,6 +492,8 @@ func Test_WWIVHeartHTML(t *testing.T) {
 		{"empty", args{}, "", false},
 		{"string", args{"hello world"}, "hello world", false},
 		{"prefix", args{"\x037Hello world"}, "<i class=\"P0 P7\">Hello world</i>", false},
+		{"glyph", args{"♥7Hello ♥2world"}, "<i class=\"P0 P7\">Hello </i><i class=\"P0 P2\">world</i>", false},
+		{"glyph literal", args{"I ♥ BBS"}, "I ♥ BBS", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -457,6 +523,14 @@ func Test_WildcatHTML(t *testing.T) {
 		{"empty", args{}, "", false},
 		{"string", args{"hello world"}, "hello world", false},
 		{"prefix", args{"@0F@Hello world"}, "<i class=\"PB0 PFF\">Hello world</i>", false},
+		{"mid", args{"a@0F@c"}, "a<i class=\"PB0 PFF\">c</i>", false},
+		{"lowercase", args{"a@0b@c"}, "a@0b@c", false},
+		{"stray bar", args{"a@|0@c"}, "a@|0@c", false},
+		{"email", args{"Email user@host.com @ 5pm"}, "Email user@host.com @ 5pm", false},
+		{"at signs", args{"@@ @ @@@ @1@"}, "@@ @ @@@ @1@", false},
+		{"legacy", args{"@0F@Hi @B@ @1F @C @CLS@"}, "<i class=\"PB0 PFF\">Hi @B@ @1F @C @CLS@</i>", false},
+		{"colored email", args{"@0F@Mail sysop@bbs.com, or @1E@me@AB.com@0F@ at 5pm"},
+			"<i class=\"PB0 PFF\">Mail sysop@bbs.com, or </i><i class=\"PB1 PFE\">me@AB.com</i><i class=\"PB0 PFF\"> at 5pm</i>", false},
 	}
 	for _, tt := range tests {
 		got := bytes.Buffer{}
@@ -487,14 +561,22 @@ func TestBBS_Remove(t *testing.T) {
 		{"incorrect", bbs.WWIVHash, args{[]byte("@X07Hello world")}, "@X07Hello world", false},
 		{"ansi", bbs.ANSI, args{[]byte("")}, "", true},
 		{"celerity", bbs.Celerity, args{[]byte("Hello |Bworld")}, "Hello world", false},
+		{"celerity control", bbs.Celerity, args{[]byte("|!Hello |Bworld|!")}, "Hello world", false},
 		{"pcboard", bbs.PCBoard, args{[]byte("@X07Hello world")}, "Hello world", false},
 		{"pcboard nl", bbs.PCBoard, args{[]byte("@X07Hello\n@X11world@X01")}, "Hello\nworld", false},
+		{"pcboard final nl", bbs.PCBoard, args{[]byte("@X07Hello\n@X11world@X01\n")}, "Hello\nworld\n", false},
+		{"pcboard code nl", bbs.PCBoard, args{[]byte("@X07Hello\n@X11world\n@X01")}, "Hello\nworld\n", false},
+		{"pcboard crlf", bbs.PCBoard, args{[]byte("@X07Hello\r\n@X11world\r\n")}, "Hello\r\nworld\r\n", false},
+		{"pcboard whitespace", bbs.PCBoard, args{[]byte("@X07Hello \t@X01  \n\n")}, "Hello \t  \n\n", false},
+		{"pcboard only codes", bbs.PCBoard, args{[]byte("@X07@X01\n")}, "\n", false},
 		{"pcboard false pos", bbs.PCBoard, args{[]byte("@X07PCBoard @X code")}, "PCBoard @X code", false},
 		{"renegade", bbs.Renegade, args{[]byte("Hello |15world")}, "Hello world", false},
 		{"telegard", bbs.Telegard, args{[]byte("`07Hello world")}, "Hello world", false},
 		{"whash", bbs.WWIVHash, args{[]byte("|#7Hello world")}, "Hello world", false},
 		{"wheart", bbs.WWIVHeart, args{[]byte("\x037Hello world")}, "Hello world", false},
+		{"wheart glyph", bbs.WWIVHeart, args{[]byte("♥7Hello ♥ world")}, "Hello ♥ world", false},
 		{"wildcat", bbs.Wildcat, args{[]byte("@0F@Hello world")}, "Hello world", false},
+		{"wildcat prose", bbs.Wildcat, args{[]byte("@0F@a@0b@c user@host")}, "a@0b@c user@host", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -509,6 +591,40 @@ func TestBBS_Remove(t *testing.T) {
 	}
 }
 
+func TestBBS_Remove_lossless(t *testing.T) {
+	// the visible text of the HTML must match the text with the color codes removed
+	tags := regexp.MustCompile(`<[^>]*>`)
+	tests := []struct {
+		b   bbs.BBS
+		src string
+	}{
+		{bbs.Celerity, "|wHello\n|S|bworld|S\n\n"},
+		{bbs.PCBoard, "@X07Hello\n@X11world@X01\n"},
+		{bbs.PCBoard, "\n@X07Hello @X11 \t\n"},
+		{bbs.Renegade, "|07Hello\n|17world|00\n "},
+		{bbs.Telegard, "`07Hello\n`11world\n"},
+		{bbs.Wildcat, "@07@Hello\n@11@world\n"},
+		{bbs.WWIVHash, "|#7Hello\n|#1world\n"},
+		{bbs.WWIVHeart, "\x037Hello\n\x031world\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			text := bytes.Buffer{}
+			if err := tt.b.Remove(&text, []byte(tt.src)...); err != nil {
+				t.Fatal(err)
+			}
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			visible := tags.ReplaceAllString(html.String(), "")
+			if visible != text.String() {
+				t.Errorf("BBS.Remove() = %q, want the HTML text %q", text.String(), visible)
+			}
+		})
+	}
+}
+
 func TestTrimControls(t *testing.T) {
 	type args struct {
 		b []byte
@@ -523,6 +639,8 @@ func TestTrimControls(t *testing.T) {
 		{"clear", args{[]byte("@CLS@Hello world.")}, []byte("Hello world.")},
 		{"pause", args{[]byte("@PAUSE@Hello world.")}, []byte("Hello world.")},
 		{"both", args{[]byte("@CLS@@PAUSE@Hello world.")}, []byte("Hello world.")},
+		{"form feed", args{[]byte("Hello\fworld.\f")}, []byte("Helloworld.")},
+		{"form feed clear", args{[]byte("\f@CLS@Hello world.")}, []byte("Hello world.")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -540,3 +658,1183 @@ func TestCelerityHTMLNil(t *testing.T) {
 		}
 	})
 }
+
+func TestEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		b        bbs.BBS
+		src      string
+		wantHTML string
+		wantText string
+	}{
+		{"plain", bbs.PCBoard, "a<b", "a&lt;b", "a<b"},
+		{"celerity", bbs.Celerity, "|wa<b", "<i class=\"PBk PFw\">a&lt;b</i>", "a<b"},
+		{"pcboard", bbs.PCBoard, "@X07a<b", "<i class=\"PB0 PF7\">a&lt;b</i>", "a<b"},
+		{"renegade", bbs.Renegade, "|07a<b", "<i class=\"P0 P7\">a&lt;b</i>", "a<b"},
+		{"telegard", bbs.Telegard, "`07a<b", "<i class=\"PB0 PF7\">a&lt;b</i>", "a<b"},
+		{"wildcat", bbs.Wildcat, "@07@a<b", "<i class=\"PB0 PF7\">a&lt;b</i>", "a<b"},
+		{"whash", bbs.WWIVHash, "|#7a<b", "<i class=\"P0 P7\">a&lt;b</i>", "a<b"},
+		{"wheart", bbs.WWIVHeart, "\x037a<b", "<i class=\"P0 P7\">a&lt;b</i>", "a<b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src)); err != nil {
+				t.Error(err)
+			}
+			if got.String() != tt.wantHTML {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.wantHTML)
+			}
+			got.Reset()
+			if err := tt.b.Remove(&got, []byte(tt.src)...); err != nil {
+				t.Error(err)
+			}
+			if got.String() != tt.wantText {
+				t.Errorf("BBS.Remove() = %q, want %q", got.String(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestHTML_mixed(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    bbs.BBS
+		wantStr string
+		wantErr bool
+	}{
+		{"ansi", ansiEsc + "0mHello", bbs.ANSI, "", true},
+		{"pcboard", "@X07Hello", bbs.PCBoard, "<i class=\"PB0 PF7\">Hello</i>", false},
+		{
+			"pcb+ans", "@CLS@@X0FHello\n" + ansiEsc + "1;31mworld", bbs.PCBoard,
+			"<i class=\"PB0 PFF\">Hello\n</i><i class=\"PB0 PFC\">world</i>", false,
+		},
+		{
+			"ans+pcb", ansiEsc + "0;34mHello\n@X4Eworld", bbs.ANSI,
+			"<i class=\"PB0 PF1\">Hello\n</i><i class=\"PB4 PFE\">world</i>", false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			got, err := bbs.HTML(&buf, strings.NewReader(tt.s))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HTML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HTML() = %v, want %v", got, tt.want)
+			}
+			if buf.String() != tt.wantStr {
+				t.Errorf("HTML() buf = %q, want %q", buf.String(), tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestBBS_TemplateHTML(t *testing.T) {
+	if _, err := bbs.ANSI.TemplateHTML(nil); err == nil {
+		t.Errorf("BBS.TemplateHTML() error = %v, want %v", err, bbs.ErrANSI)
+	}
+	src := []byte("@X07<b>Hello</b>")
+	got, err := bbs.PCBoard.TemplateHTML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := template.Must(template.New("page").Parse(`<pre>{{.}}</pre>`))
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, got); err != nil {
+		t.Fatal(err)
+	}
+	const want = "<pre><i class=\"PB0 PF7\">&lt;b&gt;Hello&lt;/b&gt;</i></pre>"
+	if buf.String() != want {
+		t.Errorf("BBS.TemplateHTML() executes to %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"lf", "a\nb\n", "a\nb\n"},
+		{"crlf", "a\r\nb\r\n", "a\nb\n"},
+		{"cr", "a\rb\r", "a\nb\n"},
+		{"mixed", "a\r\n\rb\n\r\n", "a\n\nb\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(bbs.NormalizeNewlines([]byte(tt.s)...)); got != tt.want {
+				t.Errorf("NormalizeNewlines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBBS_HTML_crlf(t *testing.T) {
+	tests := []struct {
+		b   bbs.BBS
+		src string
+	}{
+		{bbs.Celerity, "|wHello\r\n|Bworld\r\n"},
+		{bbs.PCBoard, "@X07Hello\r\n@X1Fworld\r\n"},
+		{bbs.Renegade, "|07Hello\r\n|15world\r"},
+		{bbs.Telegard, "`07Hello\r\n`1Fworld\r\n"},
+		{bbs.Wildcat, "@07@Hello\r\n@1F@world\r\n"},
+		{bbs.WWIVHash, "|#7Hello\r\n|#1world\r\n"},
+		{bbs.WWIVHeart, "\x037Hello\r\n\x031world\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := tt.b.HTML(&buf, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(buf.Bytes(), []byte("\r")) {
+				t.Errorf("BBS.HTML() = %q, contains a carriage return", buf.String())
+			}
+			if n := strings.Count(buf.String(), "\n"); n != 2 {
+				t.Errorf("BBS.HTML() = %q, has %d newlines, want 2", buf.String(), n)
+			}
+			if strings.Count(buf.String(), "<i ") != 2 {
+				t.Errorf("BBS.HTML() = %q, want 2 color elements", buf.String())
+			}
+		})
+	}
+}
+
+func TestHTML_bom(t *testing.T) {
+	const bom = "\xef\xbb\xbf"
+	const src = "@CLS@@X07Hello\r\n@X1Fworld\r\n"
+	if got := bbs.Find(strings.NewReader(bom + src)); got != bbs.PCBoard {
+		t.Errorf("Find() = %v, want %v", got, bbs.PCBoard)
+	}
+	want := bytes.Buffer{}
+	if _, err := bbs.HTML(&want, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	find, err := bbs.HTML(&buf, strings.NewReader(bom+src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if find != bbs.PCBoard {
+		t.Errorf("HTML() = %v, want %v", find, bbs.PCBoard)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("HTML() = %q, want %q", buf.String(), want.String())
+	}
+}
+
+// reader hides the io.Seeker interface of the embedded reader.
+type reader struct {
+	io.Reader
+}
+
+func TestHTML_large(t *testing.T) {
+	const lines = 10000
+	src := "@X07Hello world\n" + strings.Repeat("@X1Fmore text\n", lines)
+	want := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&want, []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{"seeker", strings.NewReader(src)},
+		{"reader", reader{strings.NewReader(src)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			got, err := bbs.HTML(&buf, tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != bbs.PCBoard {
+				t.Errorf("HTML() = %v, want %v", got, bbs.PCBoard)
+			}
+			if buf.String() != want.String() {
+				t.Errorf("HTML() rendered %d bytes, want %d bytes", buf.Len(), want.Len())
+			}
+		})
+	}
+}
+
+func TestHTML_offset(t *testing.T) {
+	r := strings.NewReader("skip@X07Hello")
+	if _, err := r.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if _, err := bbs.HTML(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	const want = "<i class=\"PB0 PF7\">Hello</i>"
+	if buf.String() != want {
+		t.Errorf("HTML() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFields_large(t *testing.T) {
+	const lines = 10000
+	src := "@X07Hello world\n" + strings.Repeat("@X1Fmore text\n", lines)
+	s, _, err := bbs.Fields(reader{strings.NewReader(src)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != lines+1 {
+		t.Errorf("Fields() = %d fields, want %d", len(s), lines+1)
+	}
+}
+
+func TestFields_allocs(t *testing.T) {
+	if race {
+		t.Skip("the race detector adds allocations")
+	}
+	const lines = 200
+	src := strings.Repeat("@X07Hello @X1Fworld, @X4Ethe PCBoard @X code.\n", lines)
+	want, _, err := bbs.Fields(reader{strings.NewReader(src)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the source is read and converted to a string once, so the allocations
+	// are about one for each field, rather than a copy and a string for each field
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, _, err := bbs.Fields(reader{strings.NewReader(src)}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if limit := float64(2 * len(want)); allocs > limit {
+		t.Errorf("Fields() = %.0f allocations, want no more than %.0f", allocs, limit)
+	}
+}
+
+func TestHTML_maxSize(t *testing.T) {
+	src := "@X07Hello world" + strings.Repeat(".", 100)
+	tests := []struct {
+		name    string
+		r       io.Reader
+		size    int64
+		wantErr error
+	}{
+		{"seeker under", strings.NewReader(src), int64(len(src)), nil},
+		{"seeker over", strings.NewReader(src), int64(len(src) - 1), bbs.ErrSize},
+		{"reader under", reader{strings.NewReader(src)}, int64(len(src)), nil},
+		{"reader over", reader{strings.NewReader(src)}, 10, bbs.ErrSize},
+		{"no limit", reader{strings.NewReader(src)}, 0, nil},
+		{"default", reader{io.LimitReader(zeros{}, bbs.MaxSize+1)}, bbs.MaxSize, bbs.ErrSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			var opts []bbs.Option
+			if tt.size != bbs.MaxSize {
+				opts = append(opts, bbs.WithMaxSize(tt.size))
+			}
+			_, err := bbs.HTML(&buf, tt.r, opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("HTML() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && buf.Len() > 0 {
+				t.Errorf("HTML() wrote %d bytes, want none", buf.Len())
+			}
+		})
+	}
+	_, _, err := bbs.Fields(strings.NewReader(src), bbs.WithMaxSize(10))
+	if !errors.Is(err, bbs.ErrSize) {
+		t.Errorf("Fields() error = %v, want %v", err, bbs.ErrSize)
+	}
+}
+
+// zeros is an endless reader of zero value bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestIsText(t *testing.T) {
+	zip := []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00\x8b\x4e\x21\x55\x00\x00\x00\x00")
+	zip = append(zip, bytes.Repeat([]byte{0x00, 0x01, 0x7f, 0x10, '@', '|', 0x02, 0xff}, 64)...)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x10\x00\x00\x00\x10\x08\x06\x00\x00\x00")
+	art, err := static.ReadFile("static/examples/hello.pcb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		b    []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"text", []byte("Hello world.\r\n"), true},
+		{"pcboard", art, true},
+		{"box drawing", bytes.Repeat([]byte("\xc9\xcd\xcd\xbb\xba\xb0\xb1\xb2\xdb\r\n"), 100), true},
+		{"ansi", []byte("\x1b[0;1;31mHello\x1b[0m\r\n\x1a"), true},
+		{"wwiv", []byte("\x037Hello \x031world"), true},
+		{"zip", zip, false},
+		{"png", png, false},
+		{"nulls", make([]byte, 100), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.IsText(tt.b); got != tt.want {
+				t.Errorf("IsText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripANSIControls(t *testing.T) {
+	const esc = "\x1b"
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"text", "Hello world", "Hello world"},
+		{"sgr", esc + "[0;1;31mHello", esc + "[0;1;31mHello"},
+		{"music", "Hello" + esc + "[MF T120 O3 L8 C D E F G\x0e world", "Hello world"},
+		{"music b", esc + "[MBT200L8CDE\x0eHello", "Hello"},
+		{"music n", esc + "[N O4 C\x0eHello", "Hello"},
+		{"save restore", esc + "[sHello" + esc + "[u world", "Hello world"},
+		{"dec save restore", esc + "7Hello" + esc + "8 world", "Hello world"},
+		{"cursor", esc + "[2J" + esc + "[1;1H" + esc + "[5CHello" + esc + "[?25l", "Hello"},
+		{"mixed", esc + "[s" + esc + "[1;33mHi" + esc + "[M C\x0e" + esc + "[u", esc + "[1;33mHi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(bbs.StripANSIControls([]byte(tt.s)...)); got != tt.want {
+				t.Errorf("StripANSIControls() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind_lineEndings(t *testing.T) {
+	long := strings.Repeat("Hello world ", 20000)
+	tests := []struct {
+		name string
+		s    string
+		want bbs.BBS
+	}{
+		{"cr", "Hello\rworld\rthe end @X07!\r", bbs.PCBoard},
+		{"crlf", "Hello\r\nworld\r\nthe end |07!\r\n", bbs.Renegade},
+		{"no newline", long + "@X07the end", bbs.PCBoard},
+		{"no newline cr", long + "\r" + long + "`07the end", bbs.Telegard},
+		{"long line", long + "\n" + long + "|wthe end\n", bbs.Celerity},
+		{"no code", long, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.Find(strings.NewReader(tt.s)); got != tt.want {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// every offset of a code must be found, including the chunk boundaries
+	for i := 4080; i < 4100; i++ {
+		s := strings.Repeat("x", i) + "@01@" + strings.Repeat("x", 5000)
+		if got := bbs.Find(strings.NewReader(s)); got != bbs.Wildcat {
+			t.Errorf("Find() at offset %d = %v, want %v", i, got, bbs.Wildcat)
+		}
+	}
+}
+
+func TestFind_boundaries(t *testing.T) {
+	// the codes of every format must be found at any offset around the chunk boundaries,
+	// no matter how the reader splits the bytes when refilling the scanner buffer
+	codes := map[string]bbs.BBS{
+		"\x1b[0m": bbs.ANSI,
+		"|w":      bbs.Celerity,
+		"@X07":    bbs.PCBoard,
+		"|07":     bbs.Renegade,
+		"`07":     bbs.Telegard,
+		"@07@":    bbs.Wildcat,
+		"|#7":     bbs.WWIVHash,
+		"♥7":      bbs.WWIVHeart,
+	}
+	readers := map[string]func(io.Reader) io.Reader{
+		"reader":   func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+		"data err": iotest.DataErrReader,
+	}
+	const chunk, overlap = 4096, 8
+	for _, boundary := range []int{chunk, 2*chunk - overlap, 3*chunk - 2*overlap} {
+		for i := boundary - 8; i <= boundary; i++ {
+			for code, want := range codes {
+				s := strings.Repeat("x", i) + code + strings.Repeat("x", chunk)
+				for name, reader := range readers {
+					if got := bbs.Find(reader(strings.NewReader(s))); got != want {
+						t.Errorf("Find() %s of %q at offset %d = %v, want %v", name, code, i, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBBS_CodeLen(t *testing.T) {
+	tests := []struct {
+		b    bbs.BBS
+		code string
+	}{
+		{bbs.ANSI, ""},
+		{bbs.Celerity, "|w"},
+		{bbs.PCBoard, "@X07"},
+		{bbs.Renegade, "|07"},
+		{bbs.Telegard, "`07"},
+		{bbs.Wildcat, "@07@"},
+		{bbs.WWIVHash, "|#7"},
+		{bbs.WWIVHeart, "\x037"},
+		{bbs.BBS(-1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			if got := tt.b.CodeLen(); got != len(tt.code) {
+				t.Errorf("BBS.CodeLen() = %d, want %d", got, len(tt.code))
+			}
+			if tt.code == "" {
+				return
+			}
+			if m := tt.b.Regexp().Find([]byte(tt.code + "Hello")); len(m) != tt.b.CodeLen() {
+				t.Errorf("BBS.CodeLen() = %d, the regexp matched %q", tt.b.CodeLen(), m)
+			}
+		})
+	}
+}
+
+func TestFindSample(t *testing.T) {
+	long := strings.Repeat("Hello world\n", 1000)
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want bbs.BBS
+	}{
+		{"first line", "@X07Hello\n" + long, 4096, bbs.PCBoard},
+		{"within", long[:100] + "|07Hello", 4096, bbs.Renegade},
+		{"after", long + "|07Hello", 4096, -1},
+		{"cut", "Hello @X07", 8, -1},
+		{"exact", "Hello @X07", 10, bbs.PCBoard},
+		{"zero", "@X07Hello", 0, -1},
+		{"negative", "@X07Hello", -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.FindSample(strings.NewReader(tt.s), tt.n); got != tt.want {
+				t.Errorf("FindSample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindScored(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         string
+		want      bbs.BBS
+		wantScore float64
+	}{
+		{"empty", "", -1, 0},
+		{"plain", "Hello world", -1, 0},
+		{"ansi", ansiEsc + "0mHello" + ansiEsc + "1m", bbs.ANSI, 2.0 / 3},
+		{"renegade one", "|07Hello world", bbs.Renegade, 0.25},
+		{"renegade many", "|07Hello |15world|23!", bbs.Renegade, 0.375},
+		{"pcboard one", "@X07Hello world", bbs.PCBoard, 0.5},
+		{"pcboard many", "@X07Hello @X1Fworld @X4E!", bbs.PCBoard, 0.75},
+		{"pcboard and wildcat", "@X07Hello @1F@world", bbs.PCBoard, 0.25},
+		{"celerity", "|wHello |Bworld", bbs.Celerity, 2.0 / 3},
+		{"registered", "\x1d7Hello \x1d2world", groupSepBBS, 0.5},
+		{"pcboard and registered", "\x1d7Hello @X1Fworld", bbs.PCBoard, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, score := bbs.FindScored(strings.NewReader(tt.s))
+			if got != tt.want {
+				t.Errorf("FindScored() = %v, want %v", got, tt.want)
+			}
+			const tolerance = 1e-9
+			if diff := score - tt.wantScore; diff > tolerance || diff < -tolerance {
+				t.Errorf("FindScored() score = %v, want %v", score, tt.wantScore)
+			}
+			if score < 0 || score > 1 {
+				t.Errorf("FindScored() score = %v, is out of range", score)
+			}
+		})
+	}
+}
+
+func TestWithUnsafeNoEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"plain", bbs.PCBoard, "a &lt; b", "a &lt; b"},
+		{"celerity", bbs.Celerity, "|wa &lt; <b>b</b>", "<i class=\"PBk PFw\">a &lt; <b>b</b></i>"},
+		{"pcboard", bbs.PCBoard, "@X07a &lt; <b>b</b>", "<i class=\"PB0 PF7\">a &lt; <b>b</b></i>"},
+		{"renegade", bbs.Renegade, "|07a &lt; <b>b</b>", "<i class=\"P0 P7\">a &lt; <b>b</b></i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := tt.b.HTML(&buf, []byte(tt.src), bbs.WithUnsafeNoEscape()); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+	buf := bytes.Buffer{}
+	if _, err := bbs.HTML(&buf, strings.NewReader("@X07a &amp; b"), bbs.WithUnsafeNoEscape()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<i class=\"PB0 PF7\">a &amp; b</i>"; buf.String() != want {
+		t.Errorf("HTML() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestBBS_Regexp(t *testing.T) {
+	if re := bbs.BBS(-1).Regexp(); re != nil {
+		t.Errorf("BBS.Regexp() = %v, want nil", re)
+	}
+	tests := []struct {
+		b     bbs.BBS
+		src   string
+		match string
+	}{
+		{bbs.ANSI, "Hi " + ansiEsc + "1;31m", ansiEsc + "1;31m"},
+		{bbs.Celerity, "Hi |w", "|w"},
+		{bbs.PCBoard, "Hi @X07", "@X07"},
+		{bbs.Renegade, "Hi |07", "|07"},
+		{bbs.Telegard, "Hi `07", "`07"},
+		{bbs.Wildcat, "Hi @07@", "@07@"},
+		{bbs.WWIVHash, "Hi |#7", "|#7"},
+		{bbs.WWIVHeart, "Hi \x037", "\x037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			re := tt.b.Regexp()
+			if re == nil {
+				t.Fatal("BBS.Regexp() = nil")
+			}
+			if re != tt.b.Regexp() {
+				t.Error("BBS.Regexp() is not cached")
+			}
+			if got := re.FindString(tt.src); got != tt.match {
+				t.Errorf("BBS.Regexp() matched %q, want %q", got, tt.match)
+			}
+		})
+	}
+}
+
+func TestWithBareReset(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"reset", bbs.PCBoard, "@X0FHello@X world",
+			"<i class=\"PB0 PFF\">Hello</i> world"},
+		{"reset newline", bbs.PCBoard, "@X0FHello@X\nworld",
+			"<i class=\"PB0 PFF\">Hello</i>\nworld"},
+		{"reset end", bbs.PCBoard, "@X0FHello@X",
+			"<i class=\"PB0 PFF\">Hello</i>"},
+		{"reset code", bbs.PCBoard, "@X0FHello@X@X1Eworld",
+			"<i class=\"PB0 PFF\">Hello</i><i class=\"PB1 PFE\">world</i>"},
+		{"prose", bbs.PCBoard, "@X0FThe PCBoard @X code",
+			"<i class=\"PB0 PFF\">The PCBoard @X code</i>"},
+		{"prose end", bbs.PCBoard, "@X0FOffer a red @X?",
+			"<i class=\"PB0 PFF\">Offer a red @X?</i>"},
+		{"combo", bbs.PCBoard, "@X07@Xcodes combo",
+			"<i class=\"PB0 PF7\">@Xcodes combo</i>"},
+		{"leading", bbs.PCBoard, "Hello@X world",
+			"Hello@X world"},
+		{"wildcat", bbs.Wildcat, "@0F@Hello@X world",
+			"<i class=\"PB0 PFF\">Hello@X world</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithBareReset()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	t.Run("disabled", func(t *testing.T) {
+		got := bytes.Buffer{}
+		if err := bbs.PCBoard.HTML(&got, []byte("@X0FHello@X world")); err != nil {
+			t.Fatal(err)
+		}
+		if want := "<i class=\"PB0 PFF\">Hello@X world</i>"; got.String() != want {
+			t.Errorf("BBS.HTML() = %q, want %q", got.String(), want)
+		}
+	})
+}
+
+func TestWithPageBreak(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		opts []bbs.Option
+		want string
+	}{
+		{"removed", bbs.PCBoard, "@X07Hello\f@CLS@@X1Fworld", nil,
+			"<i class=\"PB0 PF7\">Hello</i><i class=\"PB1 PFF\">world</i>"},
+		{"page break", bbs.PCBoard, "@X07Hello\f@CLS@@X1Fworld", []bbs.Option{bbs.WithPageBreak()},
+			"<i class=\"PB0 PF7\">Hello<br class=\"Ppage\"></i><i class=\"PB1 PFF\">world</i>"},
+		{"leading", bbs.Renegade, "\f|07Hello", []bbs.Option{bbs.WithPageBreak()},
+			"<br class=\"Ppage\"><i class=\"P0 P7\">Hello</i>"},
+		{"prefix", bbs.Celerity, "|wHello\f", []bbs.Option{bbs.WithPageBreak(), bbs.WithPrefix("bbs-")},
+			"<i class=\"bbs-Bk bbs-Fw\">Hello<br class=\"bbs-page\"></i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	if got := bbs.Find(strings.NewReader("\f@CLS@\f@X07Hello\fworld")); got != bbs.PCBoard {
+		t.Errorf("Find() = %v, want %v", got, bbs.PCBoard)
+	}
+}
+
+func TestWithLineNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"empty", bbs.PCBoard, "", ""},
+		{"plain", bbs.PCBoard, "Hello\nworld\n", "<span class=\"Pline\">1</span>Hello\n<span class=\"Pline\">2</span>world\n"},
+		{"split", bbs.PCBoard, "@X07Hello\r\nworld",
+			"<span class=\"Pline\">1</span><i class=\"PB0 PF7\">Hello\n</i>" +
+				"<span class=\"Pline\">2</span><i class=\"PB0 PF7\">world</i>"},
+		{"blank lines", bbs.Renegade, "|07Hello\n\n|15world",
+			"<span class=\"Pline\">1</span><i class=\"P0 P7\">Hello\n</i>" +
+				"<span class=\"Pline\">2</span><i class=\"P0 P7\">\n</i>" +
+				"<span class=\"Pline\">3</span><i class=\"P0 P15\">world</i>"},
+		{"mid line", bbs.Celerity, "Hi |Rthere\n|Wworld",
+			"<span class=\"Pline\">1</span>Hi <i class=\"PBk PFR\">there\n</i>" +
+				"<span class=\"Pline\">2</span><i class=\"PBk PFW\">world</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithLineNumbers()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+			if err := bbs.ValidateOutput(got.Bytes()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	// the mixed PCBoard and ANSI renderer
+	got := bytes.Buffer{}
+	if _, err := bbs.HTML(&got, strings.NewReader("@X07Hello\n\x1b[1;31mworld"), bbs.WithLineNumbers()); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(got.String(), `<span class="Pline">`); n != 2 {
+		t.Errorf("HTML() = %q, has %d line numbers, want 2", got.String(), n)
+	}
+}
+
+func TestWithLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"none", bbs.PCBoard, "@X07Hello world", "<i class=\"PB0 PF7\">Hello world</i>"},
+		{"plain", bbs.PCBoard, "see https://example.com. @X07Hi",
+			"see <a href=\"https://example.com\">https://example.com</a>. <i class=\"PB0 PF7\">Hi</i>"},
+		{"element", bbs.Renegade, "|07Visit http://bbs.example/files?a=1&b=2 now",
+			"<i class=\"P0 P7\">Visit </i><a href=\"http://bbs.example/files?a=1&amp;b=2\">" +
+				"<i class=\"P0 P7\">http://bbs.example/files?a=1&amp;b=2</i></a><i class=\"P0 P7\"> now</i>"},
+		{"straddle", bbs.PCBoard, "@X07http://exa@X0Emple.com/@X07!",
+			"<a href=\"http://example.com/\"><i class=\"PB0 PF7\">http://exa</i></a>" +
+				"<a href=\"http://example.com/\"><i class=\"PB0 PFE\">mple.com/</i></a><i class=\"PB0 PF7\">!</i>"},
+		{"no scheme", bbs.Celerity, "|wexample.com", "<i class=\"PBk PFw\">example.com</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithLinks()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+			if err := bbs.ValidateOutput(got.Bytes()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	// the links are off by default
+	got := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&got, []byte("@X07http://example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got.String(), "<a ") {
+		t.Errorf("BBS.HTML() = %q, want no links", got.String())
+	}
+}
+
+func TestWithoutBackground(t *testing.T) {
+	tests := []struct {
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{bbs.Celerity, "|S|b|YHello|S|Rworld",
+			"<i class=\"PBk PFw\"></i><i class=\"PBk PFw\">Hello</i><i class=\"PBk PFR\">world</i>"},
+		{bbs.PCBoard, "@X1FHello @XE4world", "<i class=\"PB0 PFF\">Hello </i><i class=\"PB0 PF4\">world</i>"},
+		{bbs.Renegade, "|20|15Hello", "<i class=\"P0 P0\"></i><i class=\"P0 P15\">Hello</i>"},
+		{bbs.Telegard, "`1FHello", "<i class=\"PB0 PFF\">Hello</i>"},
+		{bbs.Wildcat, "@1F@Hello", "<i class=\"PB0 PFF\">Hello</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithoutBackground()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	got := bytes.Buffer{}
+	if _, err := bbs.HTML(&got, strings.NewReader("@X07Hello \x1b[44;33mworld"), bbs.WithoutBackground()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<i class=\"PB0 PF7\">Hello </i><i class=\"PB0 PF6\">world</i>"; got.String() != want {
+		t.Errorf("HTML() = %q, want %q", got.String(), want)
+	}
+	runs, err := bbs.PCBoard.Runs([]byte("@X1FHello"), bbs.WithoutBackground())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bbs.Run{{Foreground: 15, Background: 0, Text: "Hello"}}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("BBS.Runs() = %v, want %v", runs, want)
+	}
+}
+
+func TestConvertWWIV(t *testing.T) {
+	const src = "|#7Hello \x033world ♥1!"
+	tests := []struct {
+		name string
+		to   bbs.BBS
+		want string
+	}{
+		{"hash", bbs.WWIVHash, "|#7Hello |#3world |#1!"},
+		{"heart", bbs.WWIVHeart, "\x037Hello \x033world \x031!"},
+		{"renegade", bbs.Renegade, "|07Hello |03world |01!"},
+		{"pcboard", bbs.PCBoard, src},
+		{"invalid", -1, src},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.ConvertWWIV([]byte(src), tt.to); string(got) != tt.want {
+				t.Errorf("ConvertWWIV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := bbs.ConvertWWIV([]byte("|# hash and ♥ heart"), bbs.WWIVHeart); string(got) != "|# hash and ♥ heart" {
+		t.Errorf("ConvertWWIV() = %q, want the text unchanged", got)
+	}
+}
+
+func TestBBS_Remove_replaceAll(t *testing.T) {
+	// the removal must match the regular expression replacement of every code
+	names, err := filepath.Glob(filepath.Join("testdata", "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for b := bbs.Celerity; b <= bbs.WWIVHeart; b++ {
+			got := bytes.Buffer{}
+			if err := b.Remove(&got, src...); err != nil {
+				t.Fatal(err)
+			}
+			if want := b.Regexp().ReplaceAll(src, nil); !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("%s BBS.Remove() of %s does not match ReplaceAll", b.Name(), name)
+			}
+		}
+	}
+}
+
+func BenchmarkBBS_Remove(b *testing.B) {
+	src := bytes.Repeat([]byte("@X07Hello @X1Fworld, @X4Ethe PCBoard @X code.\n"), 20000)
+	b.Run("ReplaceAll", func(b *testing.B) {
+		b.ReportAllocs()
+		re := bbs.PCBoard.Regexp()
+		for range b.N {
+			buf := bytes.Buffer{}
+			buf.Write(re.ReplaceAll(src, nil))
+		}
+	})
+	b.Run("Remove", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := bytes.Buffer{}
+			if err := bbs.PCBoard.Remove(&buf, src...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestByExtension(t *testing.T) {
+	tests := []struct {
+		ext  string
+		want bbs.BBS
+	}{
+		{".pcb", bbs.PCBoard},
+		{"pcb", bbs.PCBoard},
+		{".PCB", bbs.PCBoard},
+		{".ans", bbs.ANSI},
+		{".bbs", bbs.Wildcat},
+		{".asc", bbs.Renegade},
+		{".msg", bbs.WWIVHeart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ext, func(t *testing.T) {
+			got, err := bbs.ByExtension(tt.ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ByExtension() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	for _, ext := range []string{"", ".", ".txt", ".pcbx"} {
+		if got, err := bbs.ByExtension(ext); !errors.Is(err, bbs.ErrNone) || got != -1 {
+			t.Errorf("ByExtension(%q) = %v, %v, want -1, %v", ext, got, err, bbs.ErrNone)
+		}
+	}
+	for b := bbs.ANSI; b.Valid(); b++ {
+		for _, ext := range b.Extensions() {
+			if got, _ := bbs.ByExtension(ext); got != b {
+				t.Errorf("ByExtension(%q) = %v, want %v", ext, got, b)
+			}
+		}
+	}
+	if bbs.BBS(-1).Extensions() != nil {
+		t.Error("Extensions() of an invalid BBS is not nil")
+	}
+}
+
+func BenchmarkHTML(b *testing.B) {
+	src := bytes.Repeat([]byte("@X07Hello @X1Fworld, @X4Ethe PCBoard @X code.\n"), 200)
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, reader{bytes.NewReader(src)}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	pool := &sync.Pool{New: func() any { return &bytes.Buffer{} }}
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, reader{bytes.NewReader(src)}, bbs.WithBufferPool(pool)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestWithBufferPool(t *testing.T) {
+	const src = "@X07Hello @X1Fworld"
+	want := bytes.Buffer{}
+	if _, err := bbs.HTML(&want, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	scratch := bytes.NewBufferString("stale content")
+	pool := &sync.Pool{New: func() any { return scratch }}
+	for range 3 {
+		buf := bytes.Buffer{}
+		if _, err := bbs.HTML(&buf, reader{strings.NewReader(src)}, bbs.WithBufferPool(pool)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want.String() {
+			t.Errorf("HTML() = %q, want %q", buf.String(), want.String())
+		}
+	}
+	fields, _, err := bbs.Fields(strings.NewReader(src), bbs.WithBufferPool(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"07Hello ", "1Fworld"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Fields() = %q, want %q", fields, want)
+	}
+	// a pool of the wrong type is ignored
+	bad := &sync.Pool{New: func() any { return "not a buffer" }}
+	buf := bytes.Buffer{}
+	if _, err := bbs.HTML(&buf, strings.NewReader(src), bbs.WithBufferPool(bad)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("HTML() = %q, want %q", buf.String(), want.String())
+	}
+}
+
+func TestWithCompactWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"spaces", bbs.PCBoard, "@X07Hello    \t world",
+			`<i class="PB0 PF7">Hello world</i>`},
+		{"across elements", bbs.PCBoard, "@X07Hello  @X1F  world",
+			`<i class="PB0 PF7">Hello </i><i class="PB1 PFF">world</i>`},
+		{"empty element", bbs.PCBoard, "@X07Hello @X1F @X07world",
+			`<i class="PB0 PF7">Hello </i><i class="PB0 PF7">world</i>`},
+		{"blank lines", bbs.Renegade, "|07Hello  \r\n\r\n  \n|15world\n\n",
+			"<i class=\"P0 P7\">Hello\n</i><i class=\"P0 P15\">world\n</i>"},
+		{"newline in the next element", bbs.Celerity, "|wHello  |W\n world",
+			"<i class=\"PBk PFw\">Hello\n</i><i class=\"PBk PFW\">world</i>"},
+		{"plain", bbs.WWIVHash, "Hi   there\n\n|#1 x",
+			"Hi there\n<i class=\"P0 P1\">x</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithCompactWhitespace()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	got := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&got, []byte("@X07Hello\f\fworld"),
+		bbs.WithCompactWhitespace(), bbs.WithPageBreak()); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PB0 PF7">Hello<br class="Ppage"><br class="Ppage">world</i>`; got.String() != want {
+		t.Errorf("BBS.HTML() = %q, want %q", got.String(), want)
+	}
+}
+
+func TestWithLineEnds(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+		opts []bbs.Option
+	}{
+		{"lines", bbs.PCBoard, "@X07Hello\nthere @X1Fworld\n\n",
+			"<i class=\"PB0 PF7\">Hello</i>\n<i class=\"PB0 PF7\">there </i><i class=\"PB1 PFF\">world</i>\n\n", nil},
+		{"plain", bbs.Renegade, "Hi\nthere\n|15world", "Hi\nthere\n<i class=\"P0 P15\">world</i>", nil},
+		{"line numbers", bbs.PCBoard, "@X07Hello\nworld",
+			"<span class=\"Pline\">1</span><i class=\"PB0 PF7\">Hello</i>\n" +
+				"<span class=\"Pline\">2</span><i class=\"PB0 PF7\">world</i>", []bbs.Option{bbs.WithLineNumbers()}},
+		{"markers", bbs.Celerity, "|wHello|S\n|bworld",
+			"<i class=\"PBk PFw\">Hello</i><wbr data-bbs=\"swap\">\n<i class=\"PBb PFw\">world</i>", []bbs.Option{bbs.WithMarkers()}},
+	}
+	tagRe := regexp.MustCompile(`<[^>]*>`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), append(tt.opts, bbs.WithLineEnds())...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+			want := bytes.Buffer{}
+			if err := tt.b.HTML(&want, []byte(tt.src), tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			visible := func(p []byte) string { return string(tagRe.ReplaceAll(p, nil)) }
+			if visible(got.Bytes()) != visible(want.Bytes()) {
+				t.Errorf("BBS.HTML() visible text = %q, want %q", visible(got.Bytes()), visible(want.Bytes()))
+			}
+		})
+	}
+}
+
+func TestWithTransparentX00(t *testing.T) {
+	tests := []struct {
+		name  string
+		b     bbs.BBS
+		src   string
+		plain string
+		want  string
+	}{
+		{"separator", bbs.PCBoard, "@X1FMenu@X00 | @X0EItem",
+			`<i class="PB1 PFF">Menu</i><i class="PB0 PF0"> | </i><i class="PB0 PFE">Item</i>`,
+			`<i class="PB1 PFF">Menu</i><i class="PB1 PFF"> | </i><i class="PB0 PFE">Item</i>`},
+		{"first", bbs.PCBoard, "@X00Hello",
+			`<i class="PB0 PF0">Hello</i>`,
+			`<i class="PB0 PF7">Hello</i>`},
+		{"repeated", bbs.PCBoard, "@x4eA@X00B@X00C",
+			`<i class="PB4 PFE">A</i><i class="PB0 PF0">B</i><i class="PB0 PF0">C</i>`,
+			`<i class="PB4 PFE">A</i><i class="PB4 PFE">B</i><i class="PB4 PFE">C</i>`},
+		{"telegard", bbs.Telegard, "`1FA`00B",
+			`<i class="PB1 PFF">A</i><i class="PB0 PF0">B</i>`,
+			`<i class="PB1 PFF">A</i><i class="PB1 PFF">B</i>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.plain {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.plain)
+			}
+			got.Reset()
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithTransparentX00()); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() transparent = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	got := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&got, []byte("@X1FA@X B@X00C"), bbs.WithTransparentX00(), bbs.WithBareReset()); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PB1 PFF">A</i> B<i class="PB0 PF7">C</i>`; got.String() != want {
+		t.Errorf("BBS.HTML() after a reset = %q, want %q", got.String(), want)
+	}
+}
+
+func TestWithTransform(t *testing.T) {
+	upper := bbs.WithTransform(bytes.ToUpper)
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		opts []bbs.Option
+		want string
+	}{
+		{"upper", bbs.PCBoard, "hi @X1Fhello @x0eworld", []bbs.Option{upper},
+			`HI <i class="PB1 PFF">HELLO </i><i class="PB0 PFE">WORLD</i>`},
+		{"escaped", bbs.Renegade, "|07a&b", []bbs.Option{bbs.WithTransform(func(p []byte) []byte {
+			return bytes.ReplaceAll(p, []byte("&"), []byte("<&>"))
+		})}, `<i class="P0 P7">a&lt;&amp;&gt;b</i>`},
+		{"blocks", bbs.Celerity, "|W▓▒░", []bbs.Option{bbs.WithTransform(func(p []byte) []byte {
+			return []byte(strings.NewReplacer("▓", "#", "▒", "=", "░", ".").Replace(string(p)))
+		})}, `<i class="PBk PFW">#=.</i>`},
+		{"nil", bbs.PCBoard, "@X07hi", []bbs.Option{bbs.WithTransform(nil)}, `<i class="PB0 PF7">hi</i>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailingCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      bbs.BBS
+		src    string
+		html   string
+		plain  string
+		fields []string
+	}{
+		{"pcboard", bbs.PCBoard, "@X1FHi\n@X07", "<i class=\"PB1 PFF\">Hi\n</i>", "Hi\n", []string{"1FHi\n"}},
+		{"pcboard codes", bbs.PCBoard, "@X1FHi@X07@X00", `<i class="PB1 PFF">Hi</i>`, "Hi", []string{"1FHi"}},
+		{"renegade", bbs.Renegade, "|15Hi|16|07", `<i class="P0 P15">Hi</i>`, "Hi", []string{"15Hi"}},
+		{"celerity", bbs.Celerity, "|WHi|S|k|S|w", `<i class="PBk PFW">Hi</i>`, "Hi", []string{"WHi"}},
+		{"only codes", bbs.PCBoard, "@X1F@X07", "", "", []string{}},
+		{"short text", bbs.PCBoard, "ab@X07", "ab", "ab", []string{"ab"}},
+		{"short text codes", bbs.PCBoard, "ab@X07@X01", "ab", "ab", []string{"ab"}},
+		{"short renegade", bbs.Renegade, "x|07", "x", "x", []string{"x"}},
+		{"short celerity", bbs.Celerity, "a|w", "a", "a", []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if html.String() != tt.html {
+				t.Errorf("BBS.HTML() = %q, want %q", html.String(), tt.html)
+			}
+			plain := bytes.Buffer{}
+			if err := tt.b.Remove(&plain, []byte(tt.src)...); err != nil {
+				t.Fatal(err)
+			}
+			if plain.String() != tt.plain {
+				t.Errorf("BBS.Remove() = %q, want %q", plain.String(), tt.plain)
+			}
+			fields, _, err := bbs.Fields(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("Fields() = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func BenchmarkFields(b *testing.B) {
+	src := bytes.Repeat([]byte("@X07Hello @X1Fworld, @X4Ethe PCBoard @X code.\n"), 200)
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, _, err := bbs.Fields(reader{bytes.NewReader(src)}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("seeker", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, _, err := bbs.Fields(bytes.NewReader(src)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
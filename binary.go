@@ -0,0 +1,85 @@
+package bbs
+
+// BinaryTail is the default minimum length of the run of non-printable bytes that begins a binary tail.
+// It is conservative, as text and BBS art never contain so many consecutive control characters,
+// while the binary data appended to files, such as the padding of a transfer, often does.
+const BinaryTail = 64
+
+// binaryDensity is the share, one in binaryDensity bytes, of the non-printable bytes
+// of the high-entropy binary data, such as the compressed data of a ZIP archive or a PNG image.
+// About one in ten random bytes is non-printable, while text and BBS art have almost none.
+const binaryDensity = 16
+
+// TrimBinaryTail returns src without a binary tail, the arbitrary binary data appended to some files,
+// so rendering stops at the real content and not at a line of garbage elements.
+// The tail begins with a run of at least [BinaryTail] non-printable bytes, or with the dense
+// non-printable bytes of the high-entropy data, see [TrimBinaryTailLen].
+// A SAUCE record is not a binary tail, so it should first be removed using [TrimSAUCE].
+func TrimBinaryTail(src []byte) []byte {
+	return TrimBinaryTailLen(src, BinaryTail)
+}
+
+// TrimBinaryTailLen returns src without a binary tail that begins with a run of at least n
+// non-printable bytes. The non-printable bytes are the control characters other than the tab,
+// the line endings, the form feed, the escape of the ANSI sequences, the MS-DOS end-of-file
+// and the WWIV heart (♥), which are the same bytes that are printable to [IsText].
+// The code page 437 characters, including the block characters of BBS art, are printable.
+//
+// As every byte from 0x20 is printable, the compressed or random data of a binary tail, such as
+// an appended ZIP archive or PNG image, rarely has such a run. So when n is 32 or more, the tail
+// also begins at the first non-printable byte of a window of n bytes where at least one in 16 bytes
+// are non-printable, as long as the rest of src to the end has the same density. The text before
+// the window is kept, so a few bytes of the tail that precede its first dense window can remain.
+// If src has no binary tail, or n is less than 1, src is returned unchanged.
+func TrimBinaryTailLen(src []byte, n int) []byte {
+	if n < 1 {
+		return src
+	}
+	end := denseTail(src, n)
+	run := 0
+	for i, c := range src[:end] {
+		if isPrintable(c) {
+			run = 0
+			continue
+		}
+		run++
+		if run == n {
+			return src[:i+1-n]
+		}
+	}
+	return src[:end]
+}
+
+// denseTail returns the index of the first non-printable byte of src that begins a window of n bytes,
+// and the rest of src, in which at least one in binaryDensity bytes are non-printable,
+// or the length of src when there is no such window or n is less than 32.
+func denseTail(src []byte, n int) int {
+	least := n / binaryDensity
+	if least < 2 || len(src) < n {
+		return len(src)
+	}
+	rest, window := 0, 0
+	for i, c := range src {
+		if isPrintable(c) {
+			continue
+		}
+		rest++
+		if i < n {
+			window++
+		}
+	}
+	for i := 0; i+n <= len(src); i++ {
+		binary := !isPrintable(src[i])
+		if binary && window >= least && rest*binaryDensity >= len(src)-i {
+			return i
+		}
+		if binary {
+			window--
+			rest--
+		}
+		if i+n < len(src) && !isPrintable(src[i+n]) {
+			window++
+		}
+	}
+	return len(src)
+}
@@ -0,0 +1,111 @@
+package bbs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestTrimBinaryTail(t *testing.T) {
+	clean, err := os.ReadFile("testdata/pcboard_ansi.pcb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bbs.TrimBinaryTail(clean); !bytes.Equal(got, clean) {
+		t.Errorf("TrimBinaryTail() of a clean file removed %d bytes", len(clean)-len(got))
+	}
+	tail := append(bytes.Repeat([]byte{0}, bbs.BinaryTail), 0x4d, 0x5a, 0x90, 0x00, 0x03, 0xff, 0x01)
+	src := append(append([]byte{}, clean...), tail...)
+	if got := bbs.TrimBinaryTail(src); !bytes.Equal(got, clean) {
+		t.Errorf("TrimBinaryTail() = %d bytes, want the %d bytes of the clean file", len(got), len(clean))
+	}
+	tests := []struct {
+		name string
+		src  string
+		n    int
+		want string
+	}{
+		{"empty", "", 4, ""},
+		{"short run", "@X07Hi\x00\x00\x00", 4, "@X07Hi\x00\x00\x00"},
+		{"tail", "@X07Hi\x00\x00\x01\x02text", 4, "@X07Hi"},
+		{"first run", "@X07Hi\x00\x00\x00\x00a\x00\x00\x00\x00", 4, "@X07Hi"},
+		{"controls kept", "|#1Hi\r\n\t\f\x1b[0m\x03" + "1", 2, "|#1Hi\r\n\t\f\x1b[0m\x03" + "1"},
+		{"end-of-file", "Hi\x1a\x1a\x1a", 2, "Hi\x1a\x1a\x1a"},
+		{"cp437", "\xdb\xdb\xb0\xb1\xb2", 2, "\xdb\xdb\xb0\xb1\xb2"},
+		{"delete", "Hi\x7f\x7f", 2, "Hi"},
+		{"no threshold", "Hi\x00\x00", 0, "Hi\x00\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.TrimBinaryTailLen([]byte(tt.src), tt.n); string(got) != tt.want {
+				t.Errorf("TrimBinaryTailLen() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// noise returns a PNG image and a ZIP archive of pseudo-random data,
+// which are the realistic binary tails of the high-entropy data.
+func noise(t *testing.T) map[string][]byte {
+	t.Helper()
+	r := rand.New(rand.NewPCG(1, 2))
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(r.IntN(256))
+	}
+	pngs := bytes.Buffer{}
+	if err := png.Encode(&pngs, img); err != nil {
+		t.Fatal(err)
+	}
+	zips := bytes.Buffer{}
+	zw := zip.NewWriter(&zips)
+	w, err := zw.Create("random.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(img.Pix); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{"png": pngs.Bytes(), "zip": zips.Bytes()}
+}
+
+func TestTrimBinaryTail_dense(t *testing.T) {
+	clean, err := os.ReadFile("testdata/pcboard_ansi.pcb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bytes of the tail before its first dense window, such as the PNG signature, can remain
+	const remains = 16
+	for name, tail := range noise(t) {
+		t.Run(name, func(t *testing.T) {
+			src := append(append([]byte{}, clean...), tail...)
+			got := bbs.TrimBinaryTail(src)
+			if !bytes.HasPrefix(got, clean) || len(got) > len(clean)+remains {
+				t.Errorf("TrimBinaryTail() = %d bytes, want the %d bytes of the clean file", len(got), len(clean))
+			}
+		})
+	}
+	names, err := filepath.Glob("testdata/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		p, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bbs.TrimBinaryTail(p); len(got) != len(p) {
+			t.Errorf("TrimBinaryTail(%s) removed %d bytes of text", name, len(p)-len(got))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package bbs
+
+import "errors"
+
+// ErrBlink is returned when the blink mode is not valid.
+var ErrBlink = errors.New("blink mode is not valid")
+
+// Blink is the display of the PCBoard, Telegard and Wildcat! background color values 8 to 15,
+// and of the ANSI blink attribute. The PC text mode either blinks the text of these backgrounds,
+// or when the iCE colors are enabled, displays them as the eight bright background colors.
+type Blink int
+
+// Blink modes of the backgrounds 8 to 15.
+const (
+	BlinkAuto   Blink = iota // BlinkAuto uses the iCE colors flag of a SAUCE record, otherwise the backgrounds blink.
+	BlinkAlways              // BlinkAlways blinks the text of the backgrounds.
+	BlinkIce                 // BlinkIce displays the backgrounds as the bright iCE colors.
+)
+
+// Valid reports whether the blink mode is known.
+func (b Blink) Valid() bool {
+	return b >= BlinkAuto && b <= BlinkIce
+}
+
+// ice reports whether the backgrounds 8 to 15 of src are displayed as the iCE bright colors.
+func (c config) ice(src []byte) bool {
+	switch c.blink {
+	case BlinkAlways:
+		return false
+	case BlinkIce:
+		return true
+	}
+	const iceColors = 1 // the non-blink mode bit of the SAUCE ANSiFlags
+	s, ok := ParseSAUCE(src)
+	return ok && s.Flags&iceColors != 0
+}
@@ -0,0 +1,101 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestWithBlink(t *testing.T) {
+	const src = "@X81Hi"
+	ice := append([]byte(src), sauce("ice", 1)...)
+	tests := []struct {
+		name  string
+		src   []byte
+		mode  bbs.Blink
+		class string
+		sgr   string
+	}{
+		{"auto", []byte(src), bbs.BlinkAuto, `class="PB8 PF1"`, "\x1b[0;5;34;40mHi"},
+		{"auto sauce", ice, bbs.BlinkAuto, `class="PBI8 PF1"`, "\x1b[0;34;100mHi"},
+		{"auto no ice flag", append([]byte(src), sauce("blink", 0)...), bbs.BlinkAuto, `class="PB8 PF1"`, "\x1b[0;5;34;40mHi"},
+		{"always", ice, bbs.BlinkAlways, `class="PB8 PF1"`, "\x1b[0;5;34;40mHi"},
+		{"ice", []byte(src), bbs.BlinkIce, `class="PBI8 PF1"`, "\x1b[0;34;100mHi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := bbs.PCBoard.HTML(&buf, tt.src, bbs.WithBlink(tt.mode)); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), `<i `+tt.class+`>Hi`) {
+				t.Errorf("BBS.HTML() = %q, want the %s classes", buf.String(), tt.class)
+			}
+			buf.Reset()
+			if err := bbs.PCBoard.Terminal(&buf, tt.src, bbs.WithBlink(tt.mode)); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.sgr) {
+				t.Errorf("BBS.Terminal() = %q, want prefix %q", buf.String(), tt.sgr)
+			}
+			runs, err := bbs.PCBoard.Runs(tt.src, bbs.WithBlink(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runs[0].Background != 8 {
+				t.Errorf("BBS.Runs() background = %d, want 8", runs[0].Background)
+			}
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&buf, []byte(src), bbs.WithBlink(9)); !errors.Is(err, bbs.ErrBlink) {
+		t.Errorf("BBS.HTML() error = %v, want %v", err, bbs.ErrBlink)
+	}
+	if err := bbs.GenerateCSS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "i.PBI8 {\n  animation: none;\n  background-color: var(--darkgrey);") {
+		t.Error("GenerateCSS() is missing the iCE class PBI8")
+	}
+	buf.Reset()
+	if err := bbs.PCBoard.CSS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "i.PBIF {") {
+		t.Error("BBS.CSS() is missing the iCE class PBIF")
+	}
+}
+
+func TestWithBlink_ansi(t *testing.T) {
+	const src = "@X07Hi \x1b[5;34mthere"
+	buf := bytes.Buffer{}
+	if _, err := bbs.HTML(&buf, strings.NewReader(src), bbs.WithBlink(bbs.BlinkIce)); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PBI8 PF1">there</i>`; !strings.Contains(buf.String(), want) {
+		t.Errorf("HTML() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLowContrast_ice(t *testing.T) {
+	// blue on a blinking black background has a low contrast,
+	// while blue on the iCE dark grey background is lower still
+	const src = "@X81Hi"
+	blink, err := bbs.LowContrast([]byte(src), bbs.PCBoard, 4.5, bbs.WithBlink(bbs.BlinkAlways))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ice, err := bbs.LowContrast([]byte(src), bbs.PCBoard, 4.5, bbs.WithBlink(bbs.BlinkIce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blink) != 1 || len(ice) != 1 {
+		t.Fatalf("LowContrast() = %v and %v, want one pair each", blink, ice)
+	}
+	if blink[0].Ratio == ice[0].Ratio {
+		t.Errorf("LowContrast() ratio = %v for both blink modes, want different backgrounds", ice[0].Ratio)
+	}
+}
@@ -0,0 +1,50 @@
+package bbs_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestWithCodepage(t *testing.T) {
+	cp437 := bbs.WithCodepage(charmap.CodePage437)
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"pcboard", bbs.PCBoard, "@X0F\xda\xc4\xbf\xdb", `<i class="PB0 PFF">┌─┐█</i>`},
+		{"wwiv heart", bbs.WWIVHeart, "\x031\xb0\xb1\xb2", `<i class="P0 P1">░▒▓</i>`},
+		{"celerity", bbs.Celerity, "|w\x82t\x82", `<i class="PBk PFw">été</i>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := tt.b.HTML(&buf, []byte(tt.src), cp437); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+	buf := bytes.Buffer{}
+	if _, err := bbs.HTML(&buf, strings.NewReader("Hi \x1b[1m@X0F\xdb"), cp437); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PB0 PFF">█</i>`; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("HTML() = %q, want suffix %q", buf.String(), want)
+	}
+	runs, err := bbs.PCBoard.Runs([]byte("@X0F\xdb"), cp437)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Text != "█" {
+		t.Errorf("BBS.Runs() = %v, want the █ text", runs)
+	}
+}
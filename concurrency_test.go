@@ -0,0 +1,68 @@
+package bbs_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+// TestConcurrency renders the same and different inputs from many goroutines,
+// it should be run with the race detector, go test -race.
+func TestConcurrency(t *testing.T) {
+	srcs := []string{
+		"|wHello |Sb|Bworld",
+		"@X07Hello @X1Fworld",
+		"|07Hello |15world",
+		"`07Hello `1Fworld",
+		"@07@Hello @1F@world",
+		"|#7Hello |#1world",
+		"\x037Hello \x031world",
+	}
+	opts := [][]bbs.Option{
+		nil,
+		{bbs.WithPrefix("bbs-")},
+		{bbs.WithMarkers(), bbs.WithTheme(bbs.Amber)},
+	}
+	// the expected results are rendered before the goroutines
+	want := make([][]string, len(srcs))
+	for i, src := range srcs {
+		for _, opt := range opts {
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, strings.NewReader(src), opt...); err != nil {
+				t.Fatal(err)
+			}
+			want[i] = append(want[i], buf.String())
+		}
+	}
+	const goroutines = 8
+	wg := sync.WaitGroup{}
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, src := range srcs {
+				for j, opt := range opts {
+					// every goroutine renders the inputs in a different order
+					k := (i + g) % len(srcs)
+					buf := bytes.Buffer{}
+					if _, err := bbs.HTML(&buf, strings.NewReader(srcs[k]), opt...); err != nil {
+						t.Error(err)
+						return
+					}
+					if buf.String() != want[k][j] {
+						t.Errorf("HTML() = %q, want %q", buf.String(), want[k][j])
+					}
+					css := bytes.Buffer{}
+					if err := bbs.GenerateCSS(&css, opt...); err != nil {
+						t.Error(err)
+					}
+					_ = bbs.Find(strings.NewReader(src))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
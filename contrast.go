@@ -0,0 +1,149 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// ErrContrast is returned when the contrast ratio is not between 1 and 21.
+var ErrContrast = errors.New("contrast ratio is not between 1 and 21")
+
+// A Remap is a low contrast color pair and the foreground color that replaces it.
+type Remap struct {
+	Foreground int     // Foreground is the CGAPalette index of the low contrast text color.
+	Background int     // Background is the CGAPalette index of the background color.
+	Ratio      float64 // Ratio is the contrast ratio of the pair, between 1 and 21.
+	To         int     // To is the CGAPalette index of the replacement text color.
+}
+
+// Contrast returns the WCAG contrast ratio of the two colors, between 1 for no contrast
+// and 21 for black and white. The WCAG guidelines recommend a ratio of at least 4.5 for text.
+func Contrast(a, b color.RGBA) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance returns the WCAG relative luminance of the sRGB color.
+func luminance(c color.RGBA) float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 0xff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// LowContrast returns the distinct color pairs of src with a contrast ratio below the ratio,
+// and the readable foreground colors that replace them when using the [WithContrast] option.
+// The pairs are returned in the order of use, and the [WithTheme] and [WithBrightness] options
+// change the palette used to measure the contrast.
+func LowContrast(src []byte, b BBS, ratio float64, opts ...Option) ([]Remap, error) {
+	cfg := newConfig(opts...)
+	cfg.contrast = ratio
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	runs, err := b.Runs(src, opts...)
+	if err != nil {
+		return nil, err
+	}
+	palette, ice := cfg.palette(), cfg.ice(src)
+	remaps := []Remap{}
+	seen := map[[2]int]bool{}
+	for _, r := range runs {
+		pair := [2]int{r.Foreground, r.Background}
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		bg := b.shown(r.Background, ice)
+		if c := Contrast(palette[r.Foreground], palette[bg]); c < ratio {
+			to := readable(palette, r.Foreground, bg, ratio, b != Celerity)
+			remaps = append(remaps, Remap{Foreground: r.Foreground, Background: r.Background, Ratio: c, To: to})
+		}
+	}
+	return remaps, nil
+}
+
+// shown returns the palette index of the displayed background color.
+// The PCBoard, Telegard and Wildcat! backgrounds 8 to 15 are the blinking variants of 0 to 7,
+// unless they are displayed as the iCE bright backgrounds.
+func (b BBS) shown(background int, ice bool) int {
+	const blink = 8
+	switch b {
+	case PCBoard, Telegard, Wildcat:
+		if !ice {
+			return background % blink
+		}
+	}
+	return background
+}
+
+// readable returns the palette index of the color nearest to the foreground
+// that has at least the contrast ratio with the background, preferring the colors
+// of the same hue, such as light blue for blue or grey for dark grey. If there is
+// no such color the foreground is returned. Celerity has no dark grey, so it is only used when allowed.
+func readable(palette [16]color.RGBA, fg, bg int, ratio float64, darkGrey bool) int {
+	const black, dark, hues = 0, 8, 8
+	hue := func(i int) int {
+		if i == black || i == dark {
+			return 7 // the greys
+		}
+		return i % hues
+	}
+	res, nearest, same := fg, math.MaxFloat64, false
+	for i, c := range palette {
+		if i == dark && !darkGrey {
+			continue
+		}
+		if Contrast(c, palette[bg]) < ratio {
+			continue
+		}
+		r := float64(c.R) - float64(palette[fg].R)
+		g := float64(c.G) - float64(palette[fg].G)
+		b := float64(c.B) - float64(palette[fg].B)
+		d := r*r + g*g + b*b
+		if s := hue(i) == hue(fg); (s && !same) || (s == same && d < nearest) {
+			res, nearest, same = i, d, s
+		}
+	}
+	return res
+}
+
+// remap returns the function used by the renderers to replace the low contrast foreground colors,
+// the colors are in the notation of the format. It returns nil when the contrast is not used.
+func (c config) remap(b BBS) func(bg, fg string) string {
+	if c.contrast == 0 {
+		return nil
+	}
+	palette := c.palette()
+	return func(bg, fg string) string {
+		f, g := b.index(fg), b.shown(b.index(bg), strings.HasPrefix(bg, split.IceBackground))
+		if Contrast(palette[f], palette[g]) >= c.contrast {
+			return fg
+		}
+		to := readable(palette, f, g, c.contrast, b != Celerity)
+		switch b {
+		case Celerity:
+			if letter, ok := CelerityLetter(to); ok {
+				return string(letter)
+			}
+			return fg
+		case PCBoard, Telegard, Wildcat:
+			return fmt.Sprintf("%X", to)
+		default:
+			return strconv.Itoa(to)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestContrast(t *testing.T) {
+	black, white := bbs.CGAPalette[0], bbs.CGAPalette[15]
+	if got := bbs.Contrast(black, white); math.Abs(got-21) > 0.01 {
+		t.Errorf("Contrast() = %f, want 21", got)
+	}
+	if got := bbs.Contrast(white, black); math.Abs(got-21) > 0.01 {
+		t.Errorf("Contrast() = %f, want 21", got)
+	}
+	if got := bbs.Contrast(black, black); got != 1 {
+		t.Errorf("Contrast() = %f, want 1", got)
+	}
+}
+
+func TestWithContrast(t *testing.T) {
+	buf := bytes.Buffer{}
+	for _, ratio := range []float64{-1, 0.5, 22, math.NaN()} {
+		if err := bbs.PCBoard.HTML(&buf, []byte("@X08Hi"), bbs.WithContrast(ratio)); !errors.Is(err, bbs.ErrContrast) {
+			t.Errorf("BBS.HTML() error = %v, want %v", err, bbs.ErrContrast)
+		}
+	}
+	tests := []struct {
+		name  string
+		b     bbs.BBS
+		src   string
+		ratio float64
+		want  string
+	}{
+		{"readable", bbs.PCBoard, "@X0FHello", 4.5, "<i class=\"PB0 PFF\">Hello</i>"},
+		{"dark grey", bbs.PCBoard, "@X08Hello", 4.5, "<i class=\"PB0 PF7\">Hello</i>"},
+		{"blue on black", bbs.PCBoard, "@X01Hello", 4, "<i class=\"PB0 PF9\">Hello</i>"},
+		{"other hue", bbs.PCBoard, "@X01Hello", 4.5, "<i class=\"PB0 PF3\">Hello</i>"},
+		{"blink", bbs.PCBoard, "@X88Hello", 4.5, "<i class=\"PB8 PF7\">Hello</i>"},
+		{"renegade", bbs.Renegade, "|01Hello", 4, "<i class=\"P0 P9\">Hello</i>"},
+		{"celerity", bbs.Celerity, "|bHello", 4, "<i class=\"PBk PFB\">Hello</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), bbs.WithContrast(tt.ratio)); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLowContrast(t *testing.T) {
+	got, err := bbs.LowContrast([]byte("@X07Hi @X08there @X01world @X08!"), bbs.PCBoard, 4.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ fg, bg, to int }{{8, 0, 7}, {1, 0, 3}}
+	if len(got) != len(want) {
+		t.Fatalf("LowContrast() = %v, want %d remaps", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Foreground != w.fg || got[i].Background != w.bg || got[i].To != w.to || got[i].Ratio >= 4.5 {
+			t.Errorf("LowContrast()[%d] = %+v, want %d/%d to %d", i, got[i], w.fg, w.bg, w.to)
+		}
+	}
+	got, err = bbs.LowContrast([]byte("@X07Hi"), bbs.PCBoard, 4.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []bbs.Remap{}) {
+		t.Errorf("LowContrast() = %v, want none", got)
+	}
+	if _, err := bbs.LowContrast([]byte("@X07Hi"), bbs.PCBoard, 30); !errors.Is(err, bbs.ErrContrast) {
+		t.Errorf("LowContrast() error = %v, want %v", err, bbs.ErrContrast)
+	}
+}
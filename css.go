@@ -0,0 +1,160 @@
+package bbs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// GenerateCSS writes to buf the Cascading Style Sheets classes needed by the HTML of all the BBS formats.
+// Unlike [BBS.CSS], which uses the static stylesheets, the classes are generated from the
+// [CGAPalette] and honor the [WithPrefix], [WithTheme] and [WithBrightness] options, so the CSS always matches the HTML.
+//
+// The backgrounds of PCBoard, Telegard and Wildcat! color values 8 to 15 blink,
+// which can be disabled by setting the --timer custom property to 0ms,
+// while their iCE bright backgrounds of the [WithBlink] option never blink.
+func GenerateCSS(buf *bytes.Buffer, opts ...Option) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	w := bytes.Buffer{}
+	cfg.root(&w)
+	fmt.Fprint(&w, "\ni {\n  font-style: normal;\n}\n")
+	cfg.pcboardCSS(&w)
+	cfg.celerityCSS(&w)
+	cfg.vbarsCSS(&w)
+	cfg.verbatimCSS(&w)
+	_, err := buf.Write(w.Bytes())
+	return err
+}
+
+// UsedClasses returns the sorted, distinct CSS color classes referenced by the HTML of src,
+// so a minimal stylesheet can be created for the document, such as for emails or AMP pages.
+// The [WithPrefix] option changes the prefix of the classes.
+// An invalid or an ANSI BBS, or a src without color codes returns nil.
+func UsedClasses(src []byte, b BBS, opts ...Option) []string {
+	buf := bytes.Buffer{}
+	if err := b.HTML(&buf, src, opts...); err != nil {
+		return nil
+	}
+	classes := []string{}
+	for _, m := range elementRe.FindAllSubmatch(buf.Bytes(), -1) {
+		for _, class := range strings.Fields(string(m[1])) {
+			if !slices.Contains(classes, class) {
+				classes = append(classes, class)
+			}
+		}
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+	slices.Sort(classes)
+	return classes
+}
+
+// Regular expressions to scan the classes defined by a stylesheet.
+var (
+	cssCommentRe  = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssPreludeRe  = regexp.MustCompile(`([^{};]*)\{`)
+	cssSelectorRe = regexp.MustCompile(`\.(-?[_a-zA-Z][_a-zA-Z0-9-]*)`)
+)
+
+// MissingClasses returns the sorted, distinct classes of used that are not defined by the css stylesheet,
+// so a test can check that a stylesheet or a custom theme covers the classes of a rendered corpus,
+// such as the classes returned by [UsedClasses]. A class is defined when it is in a selector
+// of a rule of the stylesheet, for example "i.P1F" or ".P1F:hover". If every class is defined nil is returned.
+func MissingClasses(css []byte, used []string) []string {
+	defined := map[string]bool{}
+	for _, m := range cssPreludeRe.FindAllSubmatch(cssCommentRe.ReplaceAll(css, nil), -1) {
+		for _, sel := range cssSelectorRe.FindAllSubmatch(m[1], -1) {
+			defined[string(sel[1])] = true
+		}
+	}
+	var missing []string
+	for _, class := range used {
+		if !defined[class] && !slices.Contains(missing, class) {
+			missing = append(missing, class)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// root writes the custom properties of the palette colors.
+func (c config) root(w io.Writer) {
+	fmt.Fprint(w, ":root {\n")
+	for i, rgb := range c.palette() {
+		fmt.Fprintf(w, "  --%s: rgb(%d, %d, %d);\n", ColorNames[i], rgb.R, rgb.G, rgb.B)
+	}
+	fmt.Fprint(w, "  /* to disable blinking, set --timer: 0ms; */\n  --timer: 500ms;\n}\n")
+}
+
+// pcboardCSS writes the classes used by PCBoard, Telegard and Wildcat!.
+func (c config) pcboardCSS(w io.Writer) {
+	const blink = 8
+	fmt.Fprint(w, "\n/* PCBoard, Telegard and Wildcat! BBS colors */\n")
+	for i, name := range ColorNames {
+		fmt.Fprintf(w, "\ni.%sF%X {\n  color: var(--%s);\n}\n", c.prefix, i, name)
+	}
+	for i := range ColorNames {
+		name := ColorNames[i%blink]
+		switch {
+		case i == 0:
+			fmt.Fprintf(w, "\ni.%sB%X {\n  background-color: transparent;\n}\n", c.prefix, i)
+		case i < blink:
+			fmt.Fprintf(w, "\ni.%sB%X {\n  animation: none;\n  background-color: var(--%s);\n}\n",
+				c.prefix, i, name)
+		default:
+			fmt.Fprintf(w, "\ni.%sB%X {\n  animation: var(--timer) %s-blink-%s step-end infinite;\n"+
+				"  background-color: var(--%s);\n}\n", c.prefix, i, c.prefix, name, name)
+		}
+	}
+	for i, name := range ColorNames[blink:] {
+		fmt.Fprintf(w, "\ni.%sB%s%X {\n  animation: none;\n  background-color: var(--%s);\n}\n",
+			c.prefix, split.IceBackground, i+blink, name)
+	}
+	for _, name := range ColorNames[:blink] {
+		fmt.Fprintf(w, "\n@keyframes %s-blink-%s {\n  50%% {\n    color: var(--%s);\n  }\n}\n",
+			c.prefix, name, name)
+	}
+}
+
+// celerityCSS writes the classes used by Celerity.
+func (c config) celerityCSS(w io.Writer) {
+	fmt.Fprint(w, "\n/* Celerity BBS colors */\n")
+	for _, code := range []byte(celerityCodes) {
+		i, ok := CelerityColors[code]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\ni.%sF%c {\n  color: var(--%s);\n}\n", c.prefix, code, ColorNames[i])
+	}
+	for _, code := range []byte(celerityCodes) {
+		i, ok := CelerityColors[code]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\ni.%sB%c {\n  background-color: var(--%s);\n}\n", c.prefix, code, ColorNames[i])
+	}
+}
+
+// vbarsCSS writes the classes used by Renegade and WWIV.
+func (c config) vbarsCSS(w io.Writer) {
+	const background = 16
+	fmt.Fprint(w, "\n/* Renegade and WWIV BBS colors */\n")
+	for i, name := range ColorNames {
+		fmt.Fprintf(w, "\ni.%s%d {\n  color: var(--%s);\n}\n", c.prefix, i, name)
+	}
+	for i, name := range ColorNames[:8] {
+		fmt.Fprintf(w, "\ni.%s%d {\n  background-color: var(--%s);\n}\n", c.prefix, i+background, name)
+	}
+}
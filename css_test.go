@@ -0,0 +1,161 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestGenerateCSS(t *testing.T) {
+	if err := bbs.GenerateCSS(nil); !errors.Is(err, bbs.ErrBuff) {
+		t.Errorf("GenerateCSS() error = %v, want %v", err, bbs.ErrBuff)
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.GenerateCSS(&buf, bbs.WithPrefix("1bad")); !errors.Is(err, bbs.ErrPrefix) {
+		t.Errorf("GenerateCSS() error = %v, want %v", err, bbs.ErrPrefix)
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		b      bbs.BBS
+		src    string
+	}{
+		{"celerity", "", bbs.Celerity, "|S|gHello|Rworld|S|d!"},
+		{"pcboard", "", bbs.PCBoard, "@X07Hello @XF1world"},
+		{"renegade", "", bbs.Renegade, "|07Hello |20world"},
+		{"telegard", "bbs-", bbs.Telegard, "`07Hello `8Eworld"},
+		{"wildcat", "bbs-", bbs.Wildcat, "@07@Hello @1F@world"},
+		{"wwiv", "bbs-", bbs.WWIVHash, "|#7Hello |#1world"},
+	}
+	re := regexp.MustCompile(`class="([^"]+)"`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []bbs.Option
+			if tt.prefix != "" {
+				opts = append(opts, bbs.WithPrefix(tt.prefix))
+			}
+			css, html := bytes.Buffer{}, bytes.Buffer{}
+			if err := bbs.GenerateCSS(&css, opts...); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.b.HTML(&html, []byte(tt.src), opts...); err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range re.FindAllStringSubmatch(html.String(), -1) {
+				for _, class := range strings.Fields(m[1]) {
+					if !strings.Contains(css.String(), "i."+class+" {") {
+						t.Errorf("GenerateCSS() is missing the class %q", class)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBBS_CSSInline(t *testing.T) {
+	if err := bbs.PCBoard.CSSInline(nil); !errors.Is(err, bbs.ErrBuff) {
+		t.Errorf("BBS.CSSInline() error = %v, want %v", err, bbs.ErrBuff)
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.PCBoard.CSSInline(&buf); err != nil {
+		t.Fatal(err)
+	}
+	css := buf.String()
+	if strings.Contains(css, "@import") {
+		t.Error("BBS.CSSInline() contains an @import rule")
+	}
+	for _, want := range []string{"--lightblue: rgb(85, 85, 255);", "--timer: 500ms;", "@keyframes blinkingblack", "i.PF0 {"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("BBS.CSSInline() does not contain %q", want)
+		}
+	}
+	if strings.Count(css, ":root {") != 2 {
+		t.Errorf("BBS.CSSInline() contains %d :root rules, want 2", strings.Count(css, ":root {"))
+	}
+}
+
+func TestUsedClasses(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		opts []bbs.Option
+		want []string
+	}{
+		{"none", "Hello world", bbs.PCBoard, nil, nil},
+		{"ansi", "Hello world", bbs.ANSI, nil, nil},
+		{"invalid", "@X07Hello", -1, nil, nil},
+		{"pcboard", "@X07Hello @X17world @X07!", bbs.PCBoard, nil, []string{"PB0", "PB1", "PF7"}},
+		{"celerity", "|wHello |S|bworld", bbs.Celerity, nil, []string{"PBb", "PBk", "PFw"}},
+		{"renegade", "|07Hello |17world", bbs.Renegade, nil, []string{"P0", "P17", "P7"}},
+		{"prefix", "@X07Hello", bbs.PCBoard, []bbs.Option{bbs.WithPrefix("bbs-")}, []string{"bbs-B0", "bbs-F7"}},
+		{"escaped", "@X07<i class=\"PB9 PF9\">", bbs.PCBoard, nil, []string{"PB0", "PF7"}},
+		{"titles", "@X07Hello @X17world", bbs.PCBoard, []bbs.Option{bbs.WithTitles()}, []string{"PB0", "PB1", "PF7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bbs.UsedClasses([]byte(tt.src), tt.b, tt.opts...)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("UsedClasses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingClasses(t *testing.T) {
+	const css = `/* .P9 is in a comment */
+i.PB0, i.PF7:hover {
+  color: grey;
+}
+@media (min-width: 1.5em) {
+  .P17 > i {
+    background-image: url("x.P99");
+  }
+}`
+	tests := []struct {
+		name string
+		used []string
+		want []string
+	}{
+		{"none", nil, nil},
+		{"defined", []string{"PB0", "PF7", "P17"}, nil},
+		{"missing", []string{"PF7", "P9", "P99", "P9", "P1"}, []string{"P1", "P9", "P99"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.MissingClasses([]byte(css), tt.used); !slices.Equal(got, tt.want) {
+				t.Errorf("MissingClasses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	generated := bytes.Buffer{}
+	if err := bbs.GenerateCSS(&generated); err != nil {
+		t.Fatal(err)
+	}
+	examples := map[bbs.BBS]string{
+		bbs.Celerity: "celerity.txt",
+		bbs.PCBoard:  "pcboard.txt",
+		bbs.Renegade: "renegade.asc",
+		bbs.WWIVHash: "wwivhash.txt",
+	}
+	for b, name := range examples {
+		p, err := os.ReadFile(filepath.Join("static", "examples", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := bbs.UsedClasses(p, b)
+		if len(used) == 0 {
+			t.Fatalf("UsedClasses(%s) is empty", b)
+		}
+		if got := bbs.MissingClasses(generated.Bytes(), used); got != nil {
+			t.Errorf("MissingClasses() of GenerateCSS and %s = %q", b, got)
+		}
+	}
+}
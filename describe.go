@@ -0,0 +1,82 @@
+package bbs
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// DescribeColors returns the src text with the color codes of the BBS format replaced by
+// the names of the foreground and background colors in brackets, for example
+// "[grey/black]Hello [red/black]world". It is intended for accessibility alt text and debugging.
+//
+// The names are from [ColorNames], and a color label is only written when the colors change.
+// Sequences that look like color codes but fail validation, see [BBS.Diagnose],
+// are written as their raw token in brackets, for example "[@X0G]".
+// ANSI or an invalid BBS returns the src text unchanged.
+func DescribeColors(src []byte, b BBS) string {
+	if b == ANSI || !b.Valid() {
+		return string(src)
+	}
+	p := bytes.Clone(src)
+	diags := b.Diagnose(p)
+	for i := len(diags) - 1; i >= 0; i-- {
+		d := diags[i]
+		end := d.Offset + len(d.Code)
+		p = append(p[:d.Offset], append([]byte("["+d.Code+"]"), p[end:]...)...)
+	}
+	buf := bytes.Buffer{}
+	if err := b.HTML(&buf, p); err != nil {
+		return string(src)
+	}
+	s := strings.Builder{}
+	label, last := "", 0
+	out := buf.Bytes()
+	for _, m := range elementRe.FindAllSubmatchIndex(out, -1) {
+		s.WriteString(html.UnescapeString(string(out[last:m[0]])))
+		last = m[1]
+		content := html.UnescapeString(string(out[m[4]:m[5]]))
+		if content == "" {
+			continue
+		}
+		if l := describe(b, strings.Fields(string(out[m[2]:m[3]]))); l != label {
+			label = l
+			s.WriteString(l)
+		}
+		s.WriteString(content)
+	}
+	s.WriteString(html.UnescapeString(string(out[last:])))
+	return s.String()
+}
+
+// describe returns the foreground and background color names label of the CSS color classes.
+func describe(b BBS, classes []string) string {
+	const background, foreground = 0, 1
+	if len(classes) != 2 {
+		return ""
+	}
+	name := func(class string) string {
+		i := -1
+		switch b {
+		case Celerity:
+			if c, ok := CelerityColors[class[len(class)-1]]; ok {
+				i = c
+			}
+		case PCBoard, Telegard, Wildcat:
+			if n, err := strconv.ParseUint(class[len(class)-1:], 16, 8); err == nil {
+				i = int(n)
+			}
+		case Renegade, WWIVHash, WWIVHeart:
+			const firstBackground = 16
+			if n, err := strconv.Atoi(strings.TrimLeft(class, "P")); err == nil {
+				i = n % firstBackground
+			}
+		}
+		if i < 0 || i >= len(ColorNames) {
+			return class
+		}
+		return ColorNames[i]
+	}
+	return "[" + name(classes[foreground]) + "/" + name(classes[background]) + "]"
+}
@@ -0,0 +1,37 @@
+package bbs_test
+
+import (
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestDescribeColors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want string
+	}{
+		{"text", "Hello world", bbs.PCBoard, "Hello world"},
+		{"ansi", "Hello world", bbs.ANSI, "Hello world"},
+		{"pcboard", "@X07Hello @X04world", bbs.PCBoard, "[grey/black]Hello [red/black]world"},
+		{"pcboard same", "@X07Hello @X07world", bbs.PCBoard, "[grey/black]Hello world"},
+		{"pcboard adjacent", "Hi @X07@X1Eworld", bbs.PCBoard, "Hi [yellow/blue]world"},
+		{"escaped", "@X0F<b>&</b>", bbs.PCBoard, "[white/black]<b>&</b>"},
+		{"unknown", "@X07Hello @X0Gworld", bbs.PCBoard, "[grey/black]Hello [@X0G]world"},
+		{"celerity", "|wHello |S|b|S|Yworld", bbs.Celerity, "[grey/black]Hello [yellow/blue]world"},
+		{"renegade", "|07Hello |17|12world", bbs.Renegade, "[grey/black]Hello [lightred/blue]world"},
+		{"renegade unknown", "|07Hello |24world", bbs.Renegade, "[grey/black]Hello [|24]world"},
+		{"telegard", "`07Hello `4Fworld", bbs.Telegard, "[grey/black]Hello [white/red]world"},
+		{"wildcat", "@07@Hello @4F@world", bbs.Wildcat, "[grey/black]Hello [white/red]world"},
+		{"wwiv hash", "|#7Hello |#2world", bbs.WWIVHash, "[grey/black]Hello [green/black]world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.DescribeColors([]byte(tt.src), tt.b); got != tt.want {
+				t.Errorf("DescribeColors() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
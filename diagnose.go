@@ -0,0 +1,102 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrCode is returned by the [WithStrict] option when a sequence looks like a color code but fails validation.
+var ErrCode = errors.New("malformed color code")
+
+// A ParseError records the position in the source of an error, so callers can log
+// exactly where a file went wrong. The underlying sentinel error, such as [ErrCode]
+// or [ErrHTML], is returned by Unwrap so the error can be tested using errors.Is.
+type ParseError struct {
+	Offset int    // Offset is the byte position of the error within the source.
+	Format BBS    // Format is the BBS color format of the source.
+	Code   string // Code is the sequence at the offset, it is empty when unknown.
+	Err    error  // Err is the underlying error.
+}
+
+// Error returns the underlying error with the format name, the code and the offset.
+func (e *ParseError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s: %s at offset %d", e.Format.Name(), e.Err, e.Offset)
+	}
+	return fmt.Sprintf("%s: %s %q at offset %d", e.Format.Name(), e.Err, e.Code, e.Offset)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A Diagnostic describes a byte sequence that looks like a color code
+// but failed validation, so it is passed through as literal text.
+type Diagnostic struct {
+	Offset int    // Offset is the byte position of the sequence within the source.
+	Code   string // Code is the sequence as found in the source.
+}
+
+// Near miss regular expressions to match sequences that look like BBS color codes.
+const (
+	celerityLoose  = `\|[A-Za-z]`
+	pcboardLoose   = `(?i)@X[0-9A-Z]{2}`
+	renegadeLoose  = `\|\d{1,2}`
+	telegardLoose  = "(?i)`[0-9A-Z]{2}"
+	wildcatLoose   = `(?i)@[0-9A-Z]{2}@`
+	wwivHashLoose  = `\|#.?`
+	wwivHeartLoose = `(?:\x03|♥).?`
+)
+
+// looseRes are the compiled near miss regular expressions of the BBS formats.
+var looseRes = [...]*regexp.Regexp{
+	Celerity:  regexp.MustCompile(celerityLoose),
+	PCBoard:   regexp.MustCompile(pcboardLoose),
+	Renegade:  regexp.MustCompile(renegadeLoose),
+	Telegard:  regexp.MustCompile(telegardLoose),
+	Wildcat:   regexp.MustCompile(wildcatLoose),
+	WWIVHash:  regexp.MustCompile(wwivHashLoose),
+	WWIVHeart: regexp.MustCompile(wwivHeartLoose),
+}
+
+// Diagnose returns the sequences in src that look like the color codes of the BBS format
+// but fail validation, such as the out of range Renegade |24 or the PCBoard @X0G.
+// These sequences are not rendered as colors and are passed through as literal text.
+//
+// ANSI or an invalid BBS returns nil.
+func (b BBS) Diagnose(src []byte) []Diagnostic {
+	if b == ANSI || !b.Valid() {
+		return nil
+	}
+	return diagnose(src, looseRes[b], anchored[b])
+}
+
+// strict returns a ParseError of the first sequence in src that looks like
+// a color code of the BBS format but fails validation, when using the strict option.
+func (c config) strict(b BBS, src []byte) error {
+	if !c.strictly {
+		return nil
+	}
+	if diags := b.Diagnose(src); len(diags) > 0 {
+		return &ParseError{Offset: diags[0].Offset, Format: b, Code: diags[0].Code, Err: ErrCode}
+	}
+	return nil
+}
+
+// diagnose returns the sequences in src matched by the loose expression that the strict expression does not match.
+// The strict expression is anchored to the start of the sequence.
+func diagnose(src []byte, lre, sre *regexp.Regexp) []Diagnostic {
+	var diags []Diagnostic
+	for _, loc := range lre.FindAllIndex(src, -1) {
+		if sre.Match(src[loc[0]:]) {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Offset: loc[0],
+			Code:   string(src[loc[0]:loc[1]]),
+		})
+	}
+	return diags
+}
@@ -0,0 +1,101 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestBBS_Diagnose(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want []bbs.Diagnostic
+	}{
+		{"empty", bbs.PCBoard, "", nil},
+		{"invalid", -1, "@X0G", nil},
+		{"ansi", bbs.ANSI, "@X0G", nil},
+		{"valid", bbs.PCBoard, "@X07Hello @X1Fworld", nil},
+		{"prose", bbs.PCBoard, "PCBoard @X code", nil},
+		{"pcboard", bbs.PCBoard, "@X07Hello @X0Gworld", []bbs.Diagnostic{{10, "@X0G"}}},
+		{"celerity", bbs.Celerity, "|wHello |sworld|", []bbs.Diagnostic{{8, "|s"}}},
+		{"renegade", bbs.Renegade, "|07Hello |24world |5", []bbs.Diagnostic{{9, "|24"}, {18, "|5"}}},
+		{"telegard", bbs.Telegard, "`07Hello `0Zworld", []bbs.Diagnostic{{9, "`0Z"}}},
+		{"wildcat", bbs.Wildcat, "@07@Hello @GG@world", []bbs.Diagnostic{{10, "@GG@"}}},
+		{"whash", bbs.WWIVHash, "|#7Hello |#world", []bbs.Diagnostic{{9, "|#w"}}},
+		{"wheart", bbs.WWIVHeart, "\x037Hello \x03", []bbs.Diagnostic{{8, "\x03"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Diagnose([]byte(tt.src)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BBS.Diagnose() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      bbs.BBS
+		src    string
+		offset int
+		code   string
+	}{
+		{"pcboard", bbs.PCBoard, "@X07Hello @X0Gworld", 10, "@X0G"},
+		{"renegade", bbs.Renegade, "|07Hello |24world", 9, "|24"},
+		{"celerity", bbs.Celerity, "|wHello |xworld", 8, "|x"},
+		{"valid", bbs.PCBoard, "@X07Hello @X0Fworld", -1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := tt.b.HTML(&buf, []byte(tt.src), bbs.WithStrict())
+			if tt.offset < 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, bbs.ErrCode) {
+				t.Fatalf("BBS.HTML() error = %v, want %v", err, bbs.ErrCode)
+			}
+			var pe *bbs.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("BBS.HTML() error = %T, want a *ParseError", err)
+			}
+			if pe.Offset != tt.offset || pe.Code != tt.code || pe.Format != tt.b {
+				t.Errorf("ParseError = %+v, want offset %d and code %q", pe, tt.offset, tt.code)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("BBS.HTML() = %q, want no output", buf.String())
+			}
+			// without the option the sequence is literal text
+			if err := tt.b.HTML(&buf, []byte(tt.src)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := bbs.FromHTML([]byte(`Hello <i class="P0 P7">world</i> <i class="P16 P7">!</i>`), bbs.WWIVHash)
+	if !errors.Is(err, bbs.ErrHTML) {
+		t.Fatalf("FromHTML() error = %v, want %v", err, bbs.ErrHTML)
+	}
+	var pe *bbs.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("FromHTML() error = %T, want a *ParseError", err)
+	}
+	if pe.Offset != 33 {
+		t.Errorf("ParseError.Offset = %d, want 33", pe.Offset)
+	}
+	const want = `PCBoard: malformed color code "@X0G" at offset 4`
+	if s := (&bbs.ParseError{Offset: 4, Format: bbs.PCBoard, Code: "@X0G", Err: bbs.ErrCode}).Error(); s != want {
+		t.Errorf("ParseError.Error() = %q, want %q", s, want)
+	}
+}
@@ -0,0 +1,82 @@
+package bbs
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// tabWidth is the number of columns between tab stops.
+const tabWidth = 8
+
+// ansiRe matches the ANSI control sequence introducer (CSI) sequences.
+const ansiRe = `\x1b\[[0-9;?]*[ -/]*[@-~]`
+
+// Dimensions returns the maximum visible column width and the number of rows of the src text
+// when displayed in a terminal, with the color codes of the BBS format and the @CLS@ and @PAUSE@
+// control macros removed. Tabs advance to the next tab stop of every 8 columns, while the
+// other control characters have no width.
+//
+// Text that is valid UTF-8 is counted by runes, otherwise every byte is counted
+// as a single CP-437 encoded character. An invalid BBS format counts src as plain text.
+func Dimensions(src []byte, b BBS) (int, int) {
+	p := visible(src, b)
+	if len(p) == 0 {
+		return 0, 0
+	}
+	lines := bytes.Split(p, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	cols := 0
+	for _, line := range lines {
+		cols = max(cols, width(line))
+	}
+	return cols, len(lines)
+}
+
+// Columns is the standard column width of a PC/MS-DOS text mode terminal.
+const Columns = 80
+
+// IsWide reports whether the visible column width of the src text, as returned by [Dimensions],
+// exceeds the standard 80 [Columns] of a terminal. Some scene art, such as the 160 column
+// XBIN or wide ANSI art, is authored at a larger width and needs a wider layout to not wrap.
+func IsWide(src []byte, b BBS) bool {
+	cols, _ := Dimensions(src, b)
+	return cols > Columns
+}
+
+// visible returns src with the color codes and control macros removed and the newlines normalized.
+func visible(src []byte, b BBS) []byte {
+	p := NormalizeNewlines(TrimControls(src...)...)
+	if b == ANSI {
+		return ANSI.Regexp().ReplaceAll(StripANSIControls(p...), nil)
+	}
+	buf := bytes.Buffer{}
+	if err := b.Remove(&buf, p...); err != nil {
+		return p
+	}
+	return buf.Bytes()
+}
+
+// width returns the number of visible columns of the line.
+func width(line []byte) int {
+	const space = 0x20
+	decoded := utf8.Valid(line)
+	cols := 0
+	for len(line) > 0 {
+		r, size := rune(line[0]), 1
+		if decoded {
+			r, size = utf8.DecodeRune(line)
+		}
+		line = line[size:]
+		switch {
+		case r == '\t':
+			cols += tabWidth - cols%tabWidth
+		case r < space, r == 0x7f:
+			continue
+		default:
+			cols++
+		}
+	}
+	return cols
+}
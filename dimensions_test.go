@@ -0,0 +1,65 @@
+package bbs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestDimensions(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		b        bbs.BBS
+		wantCols int
+		wantRows int
+	}{
+		{"empty", "", bbs.PCBoard, 0, 0},
+		{"plain", "Hello world", -1, 11, 1},
+		{"newline", "Hello\nworld!\n", -1, 6, 2},
+		{"blank rows", "\n\n\n", -1, 0, 3},
+		{"crlf", "Hello\r\nworld!\r\n", -1, 6, 2},
+		{"pcboard", "@CLS@@X07Hello @X1Fworld\n@X07!", bbs.PCBoard, 11, 2},
+		{"celerity", "|wHello |S|bworld", bbs.Celerity, 11, 1},
+		{"renegade", "|07Hello |20world", bbs.Renegade, 11, 1},
+		{"wheart", "\x037Hello \x031world", bbs.WWIVHeart, 11, 1},
+		{"ansi", "\x1b[0;1;31mHello \x1b[2Cworld", bbs.ANSI, 11, 1},
+		{"tab", "\tHello\nab\tc", -1, 13, 2},
+		{"controls", "Hello\x07\x08 world", -1, 11, 1},
+		{"utf-8", "│ Hello │", -1, 9, 1},
+		{"cp-437", "\xb3 Hello \xb3", -1, 9, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := bbs.Dimensions([]byte(tt.src), tt.b)
+			if cols != tt.wantCols || rows != tt.wantRows {
+				t.Errorf("Dimensions() = %d, %d, want %d, %d", cols, rows, tt.wantCols, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestIsWide(t *testing.T) {
+	line := strings.Repeat("x", bbs.Columns)
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want bool
+	}{
+		{"empty", "", bbs.PCBoard, false},
+		{"80 columns", line + "\n" + line, bbs.PCBoard, false},
+		{"80 columns with codes", "@X07" + line + "@X1F", bbs.PCBoard, false},
+		{"81 columns", line + "x", bbs.PCBoard, true},
+		{"160 columns", "|07" + line + "|17" + line, bbs.Renegade, true},
+		{"tab", line[:75] + "\tx", bbs.PCBoard, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.IsWide([]byte(tt.src), tt.b); got != tt.want {
+				t.Errorf("IsWide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bbs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+)
+
+// ErrFont is returned when the web font is not a WOFF2, WOFF, TrueType or OpenType font.
+var ErrFont = errors.New("web font is not a woff2, woff, truetype or opentype font")
+
+// fontFamily is the CSS font family name of the embedded web font.
+const fontFamily = "bbs-font"
+
+// webFont is the path in the embedded static files of the default web font of a [Document],
+// the Px437 IBM VGA 8x16 font of the Ultimate Oldschool PC Font Pack, see static/font/README.md.
+const webFont = "static/font/Px437_IBM_VGA_8x16.woff2"
+
+// monospace are the fallback fonts of the <pre> element of the document.
+const monospace = `"Perfect DOS VGA 437", "Px437 IBM VGA 8x16", "Lucida Console", Consolas, monospace`
+
+// WithWebFont replaces the default web font of a [Document], the bundled Px437 IBM VGA 8x16 font,
+// with the font file. The font is embedded in the stylesheet as a data URI @font-face rule
+// and applied to the <pre> element, so the box drawing and block characters of BBS art render
+// with the glyph metrics of the font in any browser, without hosting the font. The font should be
+// an open licensed CP437 or VGA font in the WOFF2, WOFF, TrueType or OpenType format,
+// otherwise an [ErrFont] is returned. A nil font embeds no font, and the <pre> element
+// uses a stack of the common monospace fonts.
+func WithWebFont(font []byte) Option {
+	return func(c *config) {
+		c.font = font
+		c.fontSet = true
+	}
+}
+
+// fontFile returns the font of the WithWebFont option, or the default web font
+// of the embedded static files, which is nil when the font is not bundled.
+func (c config) fontFile() []byte {
+	if c.fontSet {
+		return c.font
+	}
+	font, err := static.ReadFile(webFont)
+	if err != nil {
+		return nil
+	}
+	return font
+}
+
+// fontType returns the media type of the font file, or an empty string for an unknown format.
+func fontType(font []byte) string {
+	switch {
+	case bytes.HasPrefix(font, []byte("wOF2")):
+		return "font/woff2"
+	case bytes.HasPrefix(font, []byte("wOFF")):
+		return "font/woff"
+	case bytes.HasPrefix(font, []byte("\x00\x01\x00\x00")), bytes.HasPrefix(font, []byte("true")):
+		return "font/ttf"
+	case bytes.HasPrefix(font, []byte("OTTO")):
+		return "font/otf"
+	}
+	return ""
+}
+
+// Document writes to buf a complete, standalone HTML document of the BBS color codes in src.
+// The document has the stylesheet of [GenerateCSS] and the HTML of [HTML] within a <pre> element,
+// so it displays the text as intended when opened in a browser. The title of the document
+// is the [Preview] of src, or the name of the format. The <pre> element uses the bundled
+// Px437 IBM VGA 8x16 web font, which can be replaced with the [WithWebFont] option.
+//
+// The returned BBS is the format found in src, and on error nothing is written to buf.
+func Document(buf *bytes.Buffer, src io.Reader, opts ...Option) (BBS, error) {
+	if buf == nil {
+		return -1, ErrBuff
+	}
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return -1, err
+	}
+	if src == nil {
+		return -1, ErrNone
+	}
+	src, err := cfg.gunzip(src)
+	if err != nil {
+		return -1, err
+	}
+	scratch := cfg.scratch()
+	defer cfg.release(scratch)
+	p, err := readAll(src, cfg.maxSize, scratch)
+	if err != nil {
+		return -1, err
+	}
+	body := bytes.Buffer{}
+	// the bytes are already decompressed
+	find, err := HTML(&body, bytes.NewReader(p), append(opts[:len(opts):len(opts)], WithGzip(false))...)
+	if err != nil {
+		return find, err
+	}
+	css := bytes.Buffer{}
+	if err := GenerateCSS(&css, opts...); err != nil {
+		return find, err
+	}
+	title := Preview(p, find, 60)
+	if title == "" {
+		title = find.Name()
+	}
+	meta := `<meta charset="utf-8">`
+	if cfg.xhtml {
+		meta = `<meta charset="utf-8" />`
+	}
+	w := bytes.Buffer{}
+	fmt.Fprintf(&w, "<!DOCTYPE html>\n<html>\n<head>\n%s\n<title>%s</title>\n<style>\n", meta, html.EscapeString(title))
+	w.Write(css.Bytes())
+	family := monospace
+	if font := cfg.fontFile(); font != nil {
+		fmt.Fprintf(&w, "\n@font-face {\n  font-family: %q;\n  src: url(\"data:%s;base64,%s\");\n}\n",
+			fontFamily, fontType(font), base64.StdEncoding.EncodeToString(font))
+		family = fmt.Sprintf("%q, %s", fontFamily, monospace)
+	}
+	fmt.Fprintf(&w, "\nbody {\n  background-color: black;\n  color: var(--grey);\n}\n"+
+		"\npre {\n  font-family: %s;\n  line-height: 1;\n}\n", family)
+	fmt.Fprintf(&w, "</style>\n</head>\n<body>\n<pre>")
+	w.Write(body.Bytes())
+	fmt.Fprint(&w, "</pre>\n</body>\n</html>\n")
+	_, err = buf.Write(w.Bytes())
+	return find, err
+}
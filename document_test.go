@@ -0,0 +1,122 @@
+package bbs_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestDocument(t *testing.T) {
+	buf := bytes.Buffer{}
+	find, err := bbs.Document(&buf, strings.NewReader("@X1FHello <world>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if find != bbs.PCBoard {
+		t.Errorf("Document() = %s, want %s", find, bbs.PCBoard)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Hello &lt;world&gt;</title>",
+		".PB1 {",
+		"monospace",
+		"color: var(--grey);",
+		`<pre><i class="PB1 PFF">Hello &lt;world&gt;</i></pre>`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Document() does not contain %q", want)
+		}
+	}
+	buf.Reset()
+	if _, err := bbs.Document(&buf, strings.NewReader("@X1FHello"), bbs.WithWebFont(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "@font-face") {
+		t.Error("Document() contains a @font-face rule without a web font")
+	}
+}
+
+func TestDocument_font(t *testing.T) {
+	font, err := os.ReadFile("static/font/Px437_IBM_VGA_8x16.woff2")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if _, err := bbs.Document(&buf, strings.NewReader("@X1FHello")); err != nil {
+		t.Fatal(err)
+	}
+	if font == nil {
+		if strings.Contains(buf.String(), "@font-face") {
+			t.Error("Document() contains a @font-face rule without the bundled web font")
+		}
+		return
+	}
+	want := "data:font/woff2;base64," + base64.StdEncoding.EncodeToString(font)
+	if !strings.Contains(buf.String(), want) {
+		t.Error("Document() does not embed the bundled web font")
+	}
+}
+
+func TestWithWebFont(t *testing.T) {
+	font := []byte("wOF2\x00\x01\x00\x00")
+	buf := bytes.Buffer{}
+	if _, err := bbs.Document(&buf, strings.NewReader("@X1FHello"), bbs.WithWebFont(font)); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		"@font-face {",
+		`src: url("data:font/woff2;base64,d09GMgABAAA=");`,
+		`font-family: "bbs-font", `,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Document() does not contain %q", want)
+		}
+	}
+	buf.Reset()
+	_, err := bbs.Document(&buf, strings.NewReader("@X1FHello"), bbs.WithWebFont([]byte("not a font")))
+	if !errors.Is(err, bbs.ErrFont) {
+		t.Errorf("Document() error = %v, want %v", err, bbs.ErrFont)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Document() wrote %d bytes on error", buf.Len())
+	}
+}
+
+func TestDocument_maxSize(t *testing.T) {
+	src := "@X07Hello world" + strings.Repeat(".", 100)
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr error
+	}{
+		{"under", int64(len(src)), nil},
+		{"over", 10, bbs.ErrSize},
+		{"no limit", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			_, err := bbs.Document(&buf, strings.NewReader(src), bbs.WithMaxSize(tt.size))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Document() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if buf.Len() > 0 {
+					t.Errorf("Document() wrote %d bytes, want none", buf.Len())
+				}
+				return
+			}
+			if want := strings.Repeat(".", 100) + "</i></pre>"; !strings.Contains(buf.String(), want) {
+				t.Errorf("Document() does not contain the full text %q", want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package bbs
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"strings"
+)
+
+// EmailHTML writes to buf a self-contained HTML fragment of the BBS color codes in src,
+// for the email templates and the AMP pages that do not allow the stylesheets or the class attributes.
+// The fragment is a <pre> element that contains <span> elements with inline style attributes
+// of the palette colors, so it has no class attributes, no <style> element and no <html> document.
+//
+// The colors honor the [WithTheme], [WithBrightness], [WithContrast], [WithBlink]
+// and [WithoutBackground] options. The inline styles cannot blink, so the PCBoard, Telegard
+// and Wildcat! blinking backgrounds 8 to 15 are displayed as the backgrounds 0 to 7.
+// The text is always escaped and the form feed screen breaks are removed.
+// On error, nothing is written to buf.
+func (b BBS) EmailHTML(buf *bytes.Buffer, src []byte, opts ...Option) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	cfg := newConfig(opts...)
+	runs, err := b.Runs(src, opts...)
+	if err != nil {
+		return err
+	}
+	ice := cfg.ice(src)
+	palette := cfg.palette()
+	w := bytes.Buffer{}
+	fmt.Fprintf(&w, `<pre style="background-color: %s; color: %s; font-family: monospace;">`,
+		hexColor(palette[defaultBackground]), hexColor(palette[defaultForeground]))
+	fg, bg, text := defaultForeground, defaultBackground, strings.Builder{}
+	write := func() {
+		if text.Len() == 0 {
+			return
+		}
+		s := html.EscapeString(text.String())
+		text.Reset()
+		if fg == defaultForeground && bg == defaultBackground {
+			w.WriteString(s)
+			return
+		}
+		style := "color: " + hexColor(palette[fg]) + ";"
+		if bg != defaultBackground {
+			style += " background-color: " + hexColor(palette[bg]) + ";"
+		}
+		fmt.Fprintf(&w, `<span style="%s">%s</span>`, style, s)
+	}
+	for _, r := range runs {
+		f, g := r.Foreground, b.shown(r.Background, ice)
+		if cfg.noBack {
+			g = defaultBackground
+		}
+		if cfg.contrast != 0 && Contrast(palette[f], palette[g]) < cfg.contrast {
+			f = readable(palette, f, g, cfg.contrast, b != Celerity)
+		}
+		if f != fg || g != bg {
+			write()
+			fg, bg = f, g
+		}
+		text.WriteString(strings.ReplaceAll(r.Text, "\f", ""))
+	}
+	write()
+	w.WriteString("</pre>")
+	_, err = buf.Write(w.Bytes())
+	return err
+}
+
+// hexColor returns the CSS hexadecimal notation of the color.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
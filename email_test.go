@@ -0,0 +1,56 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestBBS_EmailHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		opts []bbs.Option
+		want string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X1F<world>@X1Fagain@X07.", nil,
+			`Hi <span style="color: #ffffff; background-color: #0000aa;">&lt;world&gt;again</span>.`},
+		{"blink", bbs.PCBoard, "@X9EHello", nil,
+			`<span style="color: #ffff55; background-color: #0000aa;">Hello</span>`},
+		{"ice", bbs.PCBoard, "@X9EHello", []bbs.Option{bbs.WithBlink(bbs.BlinkIce)},
+			`<span style="color: #ffff55; background-color: #5555ff;">Hello</span>`},
+		{"no background", bbs.PCBoard, "@X1EHello", []bbs.Option{bbs.WithoutBackground()},
+			`<span style="color: #ffff55;">Hello</span>`},
+		{"renegade", bbs.Renegade, "|17|14Hello", nil,
+			`<span style="color: #ffff55; background-color: #0000aa;">Hello</span>`},
+		{"celerity", bbs.Celerity, "|YHello", nil,
+			`<span style="color: #ffff55;">Hello</span>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := tt.b.EmailHTML(&buf, []byte(tt.src), tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			s := buf.String()
+			const pre = `<pre style="background-color: #000000; color: #aaaaaa; font-family: monospace;">`
+			if want := pre + tt.want + "</pre>"; s != want {
+				t.Errorf("EmailHTML() = %q, want %q", s, want)
+			}
+			if strings.Contains(s, "class=") || strings.Contains(s, "<style") {
+				t.Errorf("EmailHTML() = %q, contains a class attribute or a style element", s)
+			}
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.ANSI.EmailHTML(&buf, []byte("\x1b[0mHello")); !errors.Is(err, bbs.ErrANSI) {
+		t.Errorf("EmailHTML() error = %v, want %v", err, bbs.ErrANSI)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("EmailHTML() wrote %q on error", buf.String())
+	}
+}
@@ -49,9 +49,9 @@ func Example() {
 	}
 	fmt.Print(buf.String())
 
-	// Output: Found 11 PCBoard @X color controls.
+	// Output: Found 10 PCBoard @X color controls.
 	//
 	// <i class="PB0 PFF">    </i><i class="PB7 PF0"> ┌─────────────┐ </i><i class="PB0 PF7">
 	// </i><i class="PB0 PFF">    </i><i class="PB7 PF0"> │ Hello </i><i class="PBF PF0">world </i><i class="PB7 PF0">│ </i><i class="PB0 PF7">
-	// </i><i class="PB0 PFF">    </i><i class="PB7 PF0"> └─────────────┘ </i><i class="PB0 PF7"></i>
+	// </i><i class="PB0 PFF">    </i><i class="PB7 PF0"> └─────────────┘ </i>
 }
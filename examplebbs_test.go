@@ -155,6 +155,12 @@ func ExampleBBS_Bytes() {
 	// Code as string @X
 }
 
+func ExampleBBS_CodeLen() {
+	src := []byte("@X0FHello")
+	fmt.Printf("%s", src[bbs.PCBoard.CodeLen():])
+	// Output: Hello
+}
+
 func ExampleBBS_CSS() {
 	var css bytes.Buffer
 	if err := bbs.PCBoard.CSS(&css); err != nil {
@@ -184,7 +190,7 @@ func ExampleBBS_HTML() {
 		return
 	}
 	fmt.Print(buf.String())
-	// Output: <i class="PB0 PF3">Hello </i><i class="PB0 PF4">world</i><i class="PB0 PF0"></i>
+	// Output: <i class="PB0 PF3">Hello </i><i class="PB0 PF4">world</i>
 }
 
 func ExampleBBS_HTML_find() {
@@ -198,7 +204,7 @@ func ExampleBBS_HTML_find() {
 		return
 	}
 	fmt.Print(buf.String())
-	// Output: <i class="PB0 PF3">Hello </i><i class="PB0 PF4">world</i><i class="PB0 PF0"></i>
+	// Output: <i class="PB0 PF3">Hello </i><i class="PB0 PF4">world</i>
 }
 
 func ExampleBBS_HTML_ansi() {
@@ -277,3 +283,58 @@ func ExampleBBS_Valid_ansi() {
 	fmt.Print(ok)
 	// Output: true
 }
+
+func ExampleBBS_Diagnose() {
+	src := []byte("|07Hello |24world")
+
+	for _, d := range bbs.Renegade.Diagnose(src) {
+		fmt.Printf("unknown code %q at offset %d", d.Code, d.Offset)
+	}
+	// Output: unknown code "|24" at offset 9
+}
+
+func ExampleWithPrefix() {
+	src := []byte("@X03Hello world")
+
+	var buf bytes.Buffer
+	if err := bbs.PCBoard.HTML(&buf, src, bbs.WithPrefix("bbs-")); err != nil {
+		fmt.Print(err)
+		return
+	}
+	fmt.Print(buf.String())
+	// Output: <i class="bbs-B0 bbs-F3">Hello world</i>
+}
+
+func ExampleGenerateCSS() {
+	var css bytes.Buffer
+	if err := bbs.GenerateCSS(&css); err != nil {
+		fmt.Print(err)
+	}
+	// print the first 3 lines of the css
+	lines := strings.Split(css.String(), "\n")
+	for i := range 3 {
+		fmt.Println(lines[i])
+	}
+	// Output: :root {
+	//   --black: rgb(0, 0, 0);
+	//   --blue: rgb(0, 0, 170);
+}
+
+func ExampleScanner() {
+	s := bbs.NewScanner(strings.NewReader("@X1FHello @X0Eworld"), bbs.PCBoard)
+	for s.Scan() {
+		t := s.Token()
+		if t.Code {
+			fmt.Printf("code %s: %s on %s\n", t.Text, bbs.ColorNames[t.Foreground], bbs.ColorNames[t.Background])
+			continue
+		}
+		fmt.Printf("text %q\n", t.Text)
+	}
+	if err := s.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output: code @X1F: white on blue
+	// text "Hello "
+	// code @X0E: yellow on black
+	// text "world"
+}
@@ -0,0 +1,84 @@
+package bbs
+
+import (
+	"bytes"
+	"io"
+)
+
+// A Detection describes the match of a BBS color format in the source, as returned by [Explain].
+type Detection struct {
+	Format  BBS     // Format is the BBS color format.
+	Matched bool    // Matched reports whether a line of the source is detected as the format.
+	Found   bool    // Found reports whether the format is the result of [Find].
+	Offset  int     // Offset is the byte position of the first color code of the format, or -1 when unmatched.
+	Count   int     // Count is the number of color codes, or of the detected lines of a registered format.
+	Score   float64 // Score is the confidence between 0 and 1 of the format, see [FindScored].
+}
+
+// Explain returns the detection of every BBS color format in the reader, in the order used by [Find],
+// to help understand why a format was found, such as why Celerity was chosen over Renegade.
+// It does not change the detection, and the [Detection] of the format returned by Find has Found set.
+//
+// Unlike Find, which returns after the first line containing a color code, the whole reader
+// up to [MaxSize] is read, so the counts and the scores use all of the text. The score increases
+// with the number of codes and is reduced by the other formats that also match, like [FindScored].
+func Explain(r io.Reader) []Detection {
+	if r == nil {
+		return nil
+	}
+	p, err := io.ReadAll(io.LimitReader(r, MaxSize))
+	if err != nil {
+		return nil
+	}
+	find := Find(bytes.NewReader(p))
+	formats, _ := detection()
+	res := make([]Detection, 0, len(formats))
+	matched := 0
+	for _, b := range formats {
+		d := explain(p, b)
+		d.Found = b == find
+		if d.Matched {
+			matched++
+		}
+		res = append(res, d)
+	}
+	for i, d := range res {
+		if !d.Matched {
+			continue
+		}
+		ambiguous := matched - 1
+		if d.Format == Renegade {
+			ambiguous++ // WWIV also uses the Renegade pipe codes
+		}
+		n := float64(d.Count)
+		res[i].Score = n / (n + 1) / float64(1+ambiguous)
+	}
+	return res
+}
+
+// explain returns the detection of the format in src, without the found state and the score.
+func explain(src []byte, b BBS) Detection {
+	d := Detection{Format: b, Offset: -1}
+	offset := 0
+	for _, line := range bytes.SplitAfter(src, []byte("\n")) {
+		if b.Detect(line) {
+			if !d.Matched {
+				d.Matched = true
+				d.Offset = offset
+				if re := b.Regexp(); re != nil {
+					if loc := re.FindIndex(line); loc != nil {
+						d.Offset += loc[0]
+					}
+				}
+			}
+			if !b.Valid() {
+				d.Count++
+			}
+		}
+		offset += len(line)
+	}
+	if d.Matched && b.Valid() {
+		d.Count = len(b.Regexp().FindAllIndex(src, -1))
+	}
+	return d
+}
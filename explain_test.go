@@ -0,0 +1,45 @@
+package bbs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestExplain(t *testing.T) {
+	got := bbs.Explain(strings.NewReader("Hello\n|07Hello |Wworld|15\n"))
+	detections := map[bbs.BBS]bbs.Detection{}
+	for _, d := range got {
+		detections[d.Format] = d
+	}
+	if len(detections) != len(got) || len(got) < 8 {
+		t.Fatalf("Explain() = %v, want every format once", got)
+	}
+	if got[0].Format != bbs.ANSI || got[1].Format != bbs.Renegade {
+		t.Errorf("Explain() is not in the order of detection: %v", got)
+	}
+	renegade := detections[bbs.Renegade]
+	if !renegade.Matched || !renegade.Found || renegade.Offset != 6 || renegade.Count != 2 {
+		t.Errorf("Explain() Renegade = %+v", renegade)
+	}
+	celerity := detections[bbs.Celerity]
+	if !celerity.Matched || celerity.Found || celerity.Offset != 15 || celerity.Count != 1 {
+		t.Errorf("Explain() Celerity = %+v", celerity)
+	}
+	if renegade.Score <= 0 || renegade.Score >= 1 || celerity.Score <= 0 || celerity.Score >= 1 {
+		t.Errorf("Explain() scores = %v and %v", renegade.Score, celerity.Score)
+	}
+	pcboard := detections[bbs.PCBoard]
+	if pcboard.Matched || pcboard.Found || pcboard.Offset != -1 || pcboard.Count != 0 || pcboard.Score != 0 {
+		t.Errorf("Explain() PCBoard = %+v", pcboard)
+	}
+	for _, d := range bbs.Explain(strings.NewReader("Hello world")) {
+		if d.Matched || d.Found {
+			t.Errorf("Explain() plain text = %+v", d)
+		}
+	}
+	if got := bbs.Explain(nil); got != nil {
+		t.Errorf("Explain(nil) = %v, want nil", got)
+	}
+}
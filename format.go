@@ -0,0 +1,152 @@
+package bbs
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// A Format is a color code format, such as the codes of a proprietary board, that can be registered
+// to extend the detection and rendering of the package. The built-in BBS formats implement the interface.
+//
+// The methods must be safe for concurrent use, see the Concurrency section.
+type Format interface {
+	// Name returns the name of the format, which must be unique.
+	Name() string
+	// Detect reports whether the line of text contains the color codes of the format.
+	Detect(line []byte) bool
+	// HTML writes to buf the HTML equivalent of the color codes in src.
+	HTML(buf *bytes.Buffer, src []byte, opts ...Option) error
+	// Remove writes to buf the src without the color codes.
+	Remove(buf *bytes.Buffer, src ...byte) error
+}
+
+// firstCustom is the BBS value of the first registered format.
+const firstCustom = WWIVHeart + 1
+
+// detectionOrder is the default order of detection of the built-in formats.
+var detectionOrder = []BBS{ANSI, Renegade, Celerity, PCBoard, Telegard, Wildcat, WWIVHash, WWIVHeart}
+
+// registry are the formats in the order of detection, and the custom formats in the order of registration.
+var registry = struct {
+	sync.RWMutex
+	formats []BBS
+	custom  []Format
+}{
+	formats: slices.Clone(detectionOrder),
+}
+
+// Register adds the custom format to the formats used by [Find] and [HTML], and returns its BBS value,
+// which works with the [BBS.Name], [BBS.String], [BBS.Detect], [BBS.HTML] and [BBS.Remove] methods.
+// By default, the registered formats are detected after the built-in formats, in the order of registration.
+// The other methods and functions, such as [BBS.Runs] and [FromHTML], only support the built-in formats,
+// and [BBS.Valid] reports false for a registered format.
+//
+// Register is intended to be called from an init function, it panics if the format is nil
+// or if its name is already used by another format.
+func Register(f Format) BBS {
+	if f == nil {
+		panic("bbs: register of a nil format")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for b := ANSI; b.Valid(); b++ {
+		if b.Name() == f.Name() {
+			panic("bbs: register of a duplicate format " + f.Name())
+		}
+	}
+	for _, c := range registry.custom {
+		if c.Name() == f.Name() {
+			panic("bbs: register of a duplicate format " + f.Name())
+		}
+	}
+	b := firstCustom + BBS(len(registry.custom))
+	registry.custom = append(registry.custom, f)
+	registry.formats = append(registry.formats, b)
+	return b
+}
+
+// custom returns the registered format of the BBS value, or nil if it is not a registered format.
+// The registry lock must not be held by the caller.
+func (b BBS) custom() Format {
+	if b < firstCustom {
+		return nil
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	if i := int(b - firstCustom); i < len(registry.custom) {
+		return registry.custom[i]
+	}
+	return nil
+}
+
+// SetDetectionOrder sets the formats that are detected first by [Find], in the order of priority,
+// so the format that wins when the text matches more than one format can be tuned for a corpus.
+// For example, []BBS{WWIVHash, Renegade} prefers the WWIV hash codes over the Renegade codes.
+// The formats not in the order are detected afterwards in their default order,
+// and a nil order restores the default order, see [Find].
+//
+// The order applies to every following call of the package, such as [HTML] and [Fields].
+// An order with an invalid or an unregistered format returns an [ErrNone] error
+// and the order of detection is unchanged.
+func SetDetectionOrder(order []BBS) error {
+	registry.Lock()
+	defer registry.Unlock()
+	formats := make([]BBS, 0, len(detectionOrder)+len(registry.custom))
+	for _, b := range order {
+		if !b.Valid() && (b < firstCustom || int(b-firstCustom) >= len(registry.custom)) {
+			return fmt.Errorf("%w: %d is not a format", ErrNone, b)
+		}
+		if !slices.Contains(formats, b) {
+			formats = append(formats, b)
+		}
+	}
+	for _, b := range detectionOrder {
+		if !slices.Contains(formats, b) {
+			formats = append(formats, b)
+		}
+	}
+	for i := range registry.custom {
+		if b := firstCustom + BBS(i); !slices.Contains(formats, b) {
+			formats = append(formats, b)
+		}
+	}
+	registry.formats = formats
+	return nil
+}
+
+// detection returns the formats in the order of detection, and whether any custom formats are registered.
+func detection() ([]BBS, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]BBS(nil), registry.formats...), len(registry.custom) > 0
+}
+
+// Detect reports whether the line of text contains the color codes of the format.
+// ANSI is detected by the control sequence introducer, the escape and left square bracket,
+// while the other built-in formats use the Is functions, such as [IsPCBoard].
+func (b BBS) Detect(line []byte) bool {
+	switch b {
+	case ANSI:
+		return bytes.Contains(line, ANSI.Bytes())
+	case Celerity:
+		return IsCelerity(line)
+	case PCBoard:
+		return IsPCBoard(line)
+	case Renegade:
+		return IsRenegade(line)
+	case Telegard:
+		return IsTelegard(line)
+	case Wildcat:
+		return IsWildcat(line)
+	case WWIVHash:
+		return IsWWIVHash(line)
+	case WWIVHeart:
+		return IsWWIVHeart(line)
+	}
+	if f := b.custom(); f != nil {
+		return f.Detect(line)
+	}
+	return false
+}
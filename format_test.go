@@ -0,0 +1,142 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+// groupSep is a custom format that uses the group separator control followed by a foreground color digit.
+type groupSep struct{}
+
+var groupSepRe = regexp.MustCompile(`\x1d([0-7])`)
+
+func (groupSep) Name() string { return "Group separator" }
+
+func (groupSep) Detect(line []byte) bool { return groupSepRe.Match(line) }
+
+func (groupSep) HTML(buf *bytes.Buffer, src []byte, _ ...bbs.Option) error {
+	locs := groupSepRe.FindAllSubmatchIndex(src, -1)
+	for i, loc := range locs {
+		end := len(src)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		fmt.Fprintf(buf, `<i class="PB0 PF%s">%s</i>`, src[loc[2]:loc[3]], src[loc[1]:end])
+	}
+	return nil
+}
+
+func (groupSep) Remove(buf *bytes.Buffer, src ...byte) error {
+	_, err := buf.Write(groupSepRe.ReplaceAll(src, nil))
+	return err
+}
+
+var groupSepBBS = bbs.Register(groupSep{})
+
+func TestRegister(t *testing.T) {
+	const src = "Hello\n\x1d1blue \x1d2green"
+	b := bbs.Find(strings.NewReader(src))
+	if b != groupSepBBS {
+		t.Fatalf("Find() = %v, want %v", b, groupSepBBS)
+	}
+	if b.Valid() {
+		t.Error("BBS.Valid() = true, want false for a registered format")
+	}
+	if b.Name() != "Group separator" || b.String() != "Group separator" {
+		t.Errorf("BBS.Name() = %q, BBS.String() = %q, want the format name", b.Name(), b.String())
+	}
+	if got := b.Slug(); got != "group-separator" {
+		t.Errorf("BBS.Slug() = %q, want %q", got, "group-separator")
+	}
+	buf := bytes.Buffer{}
+	got, err := bbs.HTML(&buf, strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PB0 PF1">blue </i><i class="PB0 PF2">green</i>`; got != b || buf.String() != want {
+		t.Errorf("HTML() = %v %q, want %v %q", got, buf.String(), b, want)
+	}
+	buf.Reset()
+	if err := b.Remove(&buf, []byte(src)...); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello\nblue green"; buf.String() != want {
+		t.Errorf("BBS.Remove() = %q, want %q", buf.String(), want)
+	}
+	if !b.Detect([]byte("\x1d7")) || b.Detect([]byte("@X07")) {
+		t.Error("BBS.Detect() of the registered format is incorrect")
+	}
+	// the built-in formats are detected first
+	if b := bbs.Find(strings.NewReader("\x1d1blue @X07grey")); b != bbs.PCBoard {
+		t.Errorf("Find() = %v, want %v", b, bbs.PCBoard)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a duplicate name did not panic")
+		}
+	}()
+	bbs.Register(groupSep{})
+}
+
+func TestBBS_Detect(t *testing.T) {
+	tests := map[bbs.BBS]string{
+		bbs.ANSI:      "\x1b[0m",
+		bbs.Celerity:  "|w",
+		bbs.PCBoard:   "@X07",
+		bbs.Renegade:  "|07",
+		bbs.Telegard:  "`07",
+		bbs.Wildcat:   "@07@",
+		bbs.WWIVHash:  "|#7",
+		bbs.WWIVHeart: "\x037",
+	}
+	for b, code := range tests {
+		if !b.Detect([]byte("Hello " + code)) {
+			t.Errorf("BBS.Detect(%q) = false, want true for %s", code, b.Name())
+		}
+		if b.Detect([]byte("Hello")) {
+			t.Errorf("BBS.Detect() = true, want false for %s", b.Name())
+		}
+	}
+	if bbs.BBS(-1).Detect([]byte("@X07")) {
+		t.Error("BBS.Detect() = true, want false for an invalid BBS")
+	}
+}
+
+func TestSetDetectionOrder(t *testing.T) {
+	t.Cleanup(func() {
+		if err := bbs.SetDetectionOrder(nil); err != nil {
+			t.Error(err)
+		}
+	})
+	const s = "|07|WHello"
+	if got := bbs.Find(strings.NewReader(s)); got != bbs.Renegade {
+		t.Errorf("Find() default = %s, want %s", got, bbs.Renegade)
+	}
+	if err := bbs.SetDetectionOrder([]bbs.BBS{bbs.Celerity, bbs.Celerity}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bbs.Find(strings.NewReader(s)); got != bbs.Celerity {
+		t.Errorf("Find() = %s, want %s", got, bbs.Celerity)
+	}
+	if got := bbs.Find(strings.NewReader("|07Hello")); got != bbs.Renegade {
+		t.Errorf("Find() unlisted = %s, want %s", got, bbs.Renegade)
+	}
+	if err := bbs.SetDetectionOrder([]bbs.BBS{bbs.Renegade, -1}); !errors.Is(err, bbs.ErrNone) {
+		t.Errorf("SetDetectionOrder() error = %v, want %v", err, bbs.ErrNone)
+	}
+	if got := bbs.Find(strings.NewReader(s)); got != bbs.Celerity {
+		t.Errorf("Find() after an error = %s, want %s", got, bbs.Celerity)
+	}
+	if err := bbs.SetDetectionOrder(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := bbs.Find(strings.NewReader(s)); got != bbs.Renegade {
+		t.Errorf("Find() reset = %s, want %s", got, bbs.Renegade)
+	}
+}
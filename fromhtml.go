@@ -0,0 +1,263 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// ErrHTML is returned when the HTML is not a rendering of the BBS format by this package.
+var ErrHTML = errors.New("html element is not a bbs color code")
+
+// elementRe matches the <i> elements and their class names created by the HTML renderers,
+// which can be followed by other attributes, such as the title of the WithTitles option.
+var elementRe = regexp.MustCompile(`(?s)<i class="([^"]*)"[^>]*>(.*?)</i>`)
+
+// labelRe matches the <code> labels of the color codes created by the WithVerbatimCodes option.
+var labelRe = regexp.MustCompile(`<code class="[_a-zA-Z0-9-]*code">[^<]*</code>`)
+
+// Regular expressions of the <wbr> markers created by the WithMarkers option,
+// the markup also matches the color elements and the labels of the color codes.
+var (
+	markupRe = regexp.MustCompile(`<wbr data-bbs="([a-z]+)"(?: /)?>|` + elementRe.String() + `|` + labelRe.String())
+	innerRe  = regexp.MustCompile(`^<wbr data-bbs="([a-z]+)"(?: /)?>`)
+)
+
+// renderedRe matches the opening tag of an <i> element with the color classes of any prefix,
+// which are the PCBoard and Celerity background and foreground classes, such as "PB0 PF7" or "PBk PFw",
+// the iCE bright background classes, such as "PBI8 PF1", or the Renegade and WWIV classes, such as "P0 P15".
+var renderedRe = regexp.MustCompile(`<i class="(?:` +
+	`[_a-zA-Z0-9-]*B(?:I?[0-9A-F]|[kbgcrmywBGCRMYW]) [_a-zA-Z0-9-]*F[0-9A-FkbgcrmywBGCRMYW]|` +
+	`[_a-zA-Z-][_a-zA-Z0-9-]*?\d{1,2} [_a-zA-Z-][_a-zA-Z0-9-]*?\d{1,2})"[ >]`)
+
+// IsRendered reports whether src already contains the HTML color elements created by this package,
+// such as <i class="PB0 PF7">, so the content is not rendered twice, which escapes and wraps the elements again.
+// It is a quick check of the characteristic class names with any prefix and does not validate the HTML.
+func IsRendered(src []byte) bool {
+	return renderedRe.Match(src)
+}
+
+// FromHTML returns the BBS color codes of the target format reconstructed from the src HTML,
+// that must be the <i> elements and CSS color classes created by the HTML renderers of this package.
+// It is the inverse of [BBS.HTML], so a document can be edited as HTML and then saved as color codes.
+// The [WithPrefix] and [WithUnsafeNoEscape] options must match the options used to create the HTML,
+// while the <wbr> markers of the [WithMarkers] option are written as their structural codes
+// and the labels of the [WithVerbatimCodes] option are skipped.
+//
+// Arbitrary HTML is not supported, the content is unescaped but any other markup is kept as text.
+// Adjacent elements with the same colors reuse the color code, while an element with a color that
+// cannot be used by the format, such as a background for WWIV codes, returns a [*ParseError]
+// with the offset of the element that wraps an [ErrHTML] error.
+func FromHTML(src []byte, b BBS, opts ...Option) ([]byte, error) {
+	if b == ANSI {
+		return nil, ErrANSI
+	}
+	if !b.Valid() {
+		return nil, ErrNone
+	}
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	// the default colors of the renderers
+	w := codeWriter{b: b, prefix: cfg.prefix, fg: "0", bg: "0"}
+	if b == Celerity {
+		w.fg, w.bg = "w", "k"
+	}
+	buf := bytes.Buffer{}
+	last := 0
+	for _, m := range markupRe.FindAllSubmatchIndex(src, -1) {
+		buf.WriteString(cfg.unescape(src[last:m[0]]))
+		last = m[1]
+		if m[2] >= 0 {
+			// a marker outside of an element is a code without content
+			if err := w.marker(&buf, string(src[m[2]:m[3]])); err != nil {
+				return nil, &ParseError{Offset: m[0], Format: b, Err: err}
+			}
+			continue
+		}
+		if m[4] < 0 {
+			// the label of a code is skipped, as the code is written from the element that follows
+			continue
+		}
+		content := src[m[6]:m[7]]
+		if inner := innerRe.FindSubmatchIndex(content); inner != nil {
+			// a marker inside of an element is the code of the content
+			if err := w.marker(&buf, string(content[inner[2]:inner[3]])); err != nil {
+				return nil, &ParseError{Offset: m[6], Format: b, Err: err}
+			}
+			w.marked = true
+			content = content[inner[1]:]
+		}
+		if err := w.write(&buf, string(src[m[4]:m[5]])); err != nil {
+			return nil, &ParseError{Offset: m[0], Format: b, Err: err}
+		}
+		buf.WriteString(cfg.unescape(content))
+	}
+	buf.WriteString(cfg.unescape(src[last:]))
+	return buf.Bytes(), nil
+}
+
+// unescape returns the HTML content as text, unless the escaping is disabled.
+func (c config) unescape(p []byte) string {
+	if c.noEscape {
+		return string(p)
+	}
+	return html.UnescapeString(string(p))
+}
+
+// codeWriter writes the color codes of the BBS format for the class names of an element.
+type codeWriter struct {
+	b      BBS
+	prefix string
+	fg, bg string // the current foreground and background colors
+	swap   bool   // the Celerity background swap
+	marked bool   // the element content has a marker
+}
+
+// write writes to buf the color code of the class names.
+func (w *codeWriter) write(buf *bytes.Buffer, class string) error {
+	fields := strings.Fields(class)
+	if len(fields) != 2 {
+		return fmt.Errorf("%w: %q", ErrHTML, class)
+	}
+	marked := w.marked
+	w.marked = false
+	switch w.b {
+	case Celerity:
+		bg, fg, err := w.colors(fields, "B", "F")
+		if err != nil {
+			return err
+		}
+		w.celerity(buf, bg, fg, marked)
+		return nil
+	case PCBoard, Telegard, Wildcat:
+		bg, fg, err := w.colors(fields, "B", "F")
+		if err != nil {
+			return err
+		}
+		return w.pcboard(buf, bg, fg, class)
+	case Renegade, WWIVHash, WWIVHeart:
+		bg, fg, err := w.colors(fields, "", "")
+		if err != nil {
+			return err
+		}
+		return w.vbars(buf, bg, fg, class)
+	}
+	return ErrNone
+}
+
+// marker writes to buf the structural code of the <wbr> marker name.
+func (w *codeWriter) marker(buf *bytes.Buffer, name string) error {
+	switch {
+	case w.b == Celerity && name == split.MarkerSwap:
+		buf.WriteString("|S")
+		w.swap = !w.swap
+	case w.b == Celerity && name == split.MarkerControl:
+		buf.WriteString("|!")
+	case w.b == PCBoard && name == split.MarkerReset:
+		buf.WriteString("@X")
+	default:
+		return fmt.Errorf("%w: %q marker is not supported by %s", ErrHTML, name, w.b.Name())
+	}
+	return nil
+}
+
+// colors returns the background and foreground colors of the class names.
+func (w *codeWriter) colors(fields []string, bgs, fgs string) (string, string, error) {
+	bg, okb := strings.CutPrefix(fields[0], w.prefix+bgs)
+	fg, okf := strings.CutPrefix(fields[1], w.prefix+fgs)
+	if !okb || !okf || bg == "" || fg == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrHTML, strings.Join(fields, " "))
+	}
+	return bg, fg, nil
+}
+
+// celerity writes the Celerity codes that change the colors, using the |S swap for the background.
+// An element that follows a marker with unchanged colors is the content of the marker code.
+func (w *codeWriter) celerity(buf *bytes.Buffer, bg, fg string, marked bool) {
+	const swap = "|S"
+	if fg != w.fg {
+		if w.swap {
+			buf.WriteString(swap)
+			w.swap = false
+		}
+		buf.WriteString("|" + fg)
+	}
+	if bg != w.bg {
+		if !w.swap {
+			buf.WriteString(swap)
+			w.swap = true
+		}
+		buf.WriteString("|" + bg)
+	}
+	if fg == w.fg && bg == w.bg && !marked {
+		// an element with unchanged colors repeats the code of the current color
+		if w.swap {
+			buf.WriteString("|" + bg)
+		} else {
+			buf.WriteString("|" + fg)
+		}
+	}
+	w.fg, w.bg = fg, bg
+}
+
+// pcboard writes the PCBoard, Telegard or Wildcat! code of the colors.
+// The iCE bright backgrounds use the same codes as the blinking backgrounds.
+func (w *codeWriter) pcboard(buf *bytes.Buffer, bg, fg, class string) error {
+	bg = strings.TrimPrefix(bg, split.IceBackground)
+	if len(bg) != 1 || len(fg) != 1 || !isHex(bg[0]) || !isHex(fg[0]) {
+		return fmt.Errorf("%w: %q", ErrHTML, class)
+	}
+	buf.Write(w.b.Bytes())
+	buf.WriteString(bg + fg)
+	if w.b == Wildcat {
+		buf.WriteString("@")
+	}
+	return nil
+}
+
+// vbars writes the Renegade or WWIV code that changes the colors.
+// Both of the WWIV formats only support the foreground colors 0 to 9.
+func (w *codeWriter) vbars(buf *bytes.Buffer, bg, fg, class string) error {
+	b, errb := strconv.Atoi(bg)
+	f, errf := strconv.Atoi(fg)
+	if errb != nil || errf != nil {
+		return fmt.Errorf("%w: %q", ErrHTML, class)
+	}
+	codes := []string{}
+	switch {
+	case w.b == WWIVHash || w.b == WWIVHeart:
+		if bg != w.bg {
+			return fmt.Errorf("%w: %q is not supported by %s", ErrHTML, class, w.b.Name())
+		}
+		codes = append(codes, string(w.b.Bytes())+fg)
+	case bg != w.bg:
+		codes = append(codes, fmt.Sprintf("|%02d", b))
+		if fg != w.fg {
+			codes = append(codes, fmt.Sprintf("|%02d", f))
+		}
+	default:
+		codes = append(codes, fmt.Sprintf("|%02d", f))
+	}
+	re := w.b.Regexp()
+	for _, code := range codes {
+		if loc := re.FindStringIndex(code); loc == nil || loc[0] != 0 || loc[1] != len(code) {
+			return fmt.Errorf("%w: %q is not supported by %s", ErrHTML, class, w.b.Name())
+		}
+		buf.WriteString(code)
+	}
+	w.fg, w.bg = fg, bg
+	return nil
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return strings.IndexByte("0123456789ABCDEFabcdef", c) >= 0
+}
@@ -0,0 +1,189 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestFromHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+	}{
+		{"text", bbs.PCBoard, "Hello <world> & friends"},
+		{"celerity", bbs.Celerity, "Hi |rthere|S|b blue |S|Wwhite & <b>"},
+		{"celerity adjacent", bbs.Celerity, "|r|y|bHi"},
+		{"pcboard", bbs.PCBoard, "Hi @X07there @X1F<blue>\n@X07!"},
+		{"pcboard adjacent", bbs.PCBoard, "@X07@X11@X1FHi"},
+		{"renegade", bbs.Renegade, "Hi |07there |17|15blue & white|03!"},
+		{"telegard", bbs.Telegard, "Hi `07there `1Fblue"},
+		{"wildcat", bbs.Wildcat, "Hi @07@there @1F@blue"},
+		{"wwiv hash", bbs.WWIVHash, "Hi |#7there |#3blue"},
+		{"wwiv heart", bbs.WWIVHeart, "Hi \x037there \x033blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := bbs.FromHTML(html.Bytes(), tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.src {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.src)
+			}
+		})
+	}
+}
+
+func TestFromHTML_options(t *testing.T) {
+	const src = "@X07a &lt; b"
+	opts := []bbs.Option{bbs.WithPrefix("bbs-"), bbs.WithUnsafeNoEscape()}
+	html := bytes.Buffer{}
+	if err := bbs.PCBoard.HTML(&html, []byte(src), opts...); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bbs.FromHTML(html.Bytes(), bbs.PCBoard, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != src {
+		t.Errorf("FromHTML() = %q, want %q", got, src)
+	}
+}
+
+func TestFromHTML_ice(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X9Ebright @X1Fblue @XF0grey"},
+		{"telegard", bbs.Telegard, "Hi `9Ebright `1Fblue"},
+		{"wildcat", bbs.Wildcat, "Hi @9E@bright @1F@blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src), bbs.WithBlink(bbs.BlinkIce)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := bbs.FromHTML(html.Bytes(), tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.src {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.src)
+			}
+		})
+	}
+}
+
+func TestFromHTML_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       bbs.BBS
+		html    string
+		wantErr error
+	}{
+		{"ansi", bbs.ANSI, "", bbs.ErrANSI},
+		{"invalid", -1, "", bbs.ErrNone},
+		{"prefix", bbs.PCBoard, "<i class=\"XB0 XF7\">Hi</i>", bbs.ErrHTML},
+		{"classes", bbs.PCBoard, "<i class=\"PB0\">Hi</i>", bbs.ErrHTML},
+		{"pcboard color", bbs.PCBoard, "<i class=\"PBG PF7\">Hi</i>", bbs.ErrHTML},
+		{"renegade color", bbs.Renegade, "<i class=\"P0 P24\">Hi</i>", bbs.ErrHTML},
+		{"wwiv background", bbs.WWIVHash, "<i class=\"P17 P7\">Hi</i>", bbs.ErrHTML},
+		{"wwiv foreground", bbs.WWIVHeart, "<i class=\"P0 P12\">Hi</i>", bbs.ErrHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := bbs.FromHTML([]byte(tt.html), tt.b); !errors.Is(err, tt.wantErr) {
+				t.Errorf("FromHTML() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithMarkers(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		html string
+	}{
+		{"swap", bbs.Celerity, "|S|b|S|wHi |Sthere",
+			"<wbr data-bbs=\"swap\"><i class=\"PBb PFw\"></i><wbr data-bbs=\"swap\"><i class=\"PBb PFw\">Hi </i>" +
+				"<i class=\"PBb PFw\"><wbr data-bbs=\"swap\">there</i>"},
+		{"swap content", bbs.Celerity, "|S|b|SHi", "<wbr data-bbs=\"swap\"><i class=\"PBb PFw\"></i>" +
+			"<i class=\"PBb PFw\"><wbr data-bbs=\"swap\">Hi</i>"},
+		{"control", bbs.Celerity, "|!|wHi|!", "<wbr data-bbs=\"control\"><i class=\"PBk PFw\">Hi</i><wbr data-bbs=\"control\">"},
+		{"control content", bbs.Celerity, "|wHi|!there", "<i class=\"PBk PFw\">Hi</i><i class=\"PBk PFw\"><wbr data-bbs=\"control\">there</i>"},
+		{"reset", bbs.PCBoard, "@X0FHi@X there", "<i class=\"PB0 PFF\">Hi</i><wbr data-bbs=\"reset\"> there"},
+	}
+	opts := []bbs.Option{bbs.WithMarkers(), bbs.WithBareReset()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src), opts...); err != nil {
+				t.Fatal(err)
+			}
+			if html.String() != tt.html {
+				t.Errorf("BBS.HTML() = %q, want %q", html.String(), tt.html)
+			}
+			got, err := bbs.FromHTML(html.Bytes(), tt.b, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.src {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.src)
+			}
+		})
+	}
+	if _, err := bbs.FromHTML([]byte("<wbr data-bbs=\"swap\">"), bbs.PCBoard); !errors.Is(err, bbs.ErrHTML) {
+		t.Errorf("FromHTML() error = %v, want %v", err, bbs.ErrHTML)
+	}
+}
+
+func TestIsRendered(t *testing.T) {
+	for b := bbs.Celerity; b.Valid(); b++ {
+		src := map[bbs.BBS]string{
+			bbs.Celerity:  "|WHello",
+			bbs.PCBoard:   "@X1FHello",
+			bbs.Renegade:  "|15|16Hello",
+			bbs.Telegard:  "`1FHello",
+			bbs.Wildcat:   "@1F@Hello",
+			bbs.WWIVHash:  "|#1Hello",
+			bbs.WWIVHeart: "\x031Hello",
+		}[b]
+		for _, opts := range [][]bbs.Option{nil, {bbs.WithPrefix("bbs-")}, {bbs.WithBlink(bbs.BlinkIce)}} {
+			html := bytes.Buffer{}
+			if err := b.HTML(&html, []byte(src), opts...); err != nil {
+				t.Fatal(err)
+			}
+			if !bbs.IsRendered(html.Bytes()) {
+				t.Errorf("IsRendered(%q) = false, want true", html.String())
+			}
+			if bbs.IsRendered([]byte(src)) {
+				t.Errorf("IsRendered(%q) = true, want false", src)
+			}
+			if escaped := template.HTMLEscapeString(html.String()); bbs.IsRendered([]byte(escaped)) {
+				t.Errorf("IsRendered(%q) = true, want false for the escaped elements", escaped)
+			}
+		}
+	}
+	for _, s := range []string{"", "Hello", "<i>Hello</i>", `<i class="note">Hello</i>`, `<b class="PB0 PF7">Hi</b>`} {
+		if bbs.IsRendered([]byte(s)) {
+			t.Errorf("IsRendered(%q) = true, want false", s)
+		}
+	}
+	if !bbs.IsRendered([]byte(`<i class="PBI8 PF1">Hello</i>`)) {
+		t.Error("IsRendered() = false, want true for the iCE classes")
+	}
+}
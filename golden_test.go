@@ -0,0 +1,127 @@
+package bbs_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// update rewrites the golden files, run with: go test -run TestGolden -update
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden renders src with the options and compares the HTML to the golden file at path,
+// which is rewritten when the update flag is set. It returns the format found in src.
+func golden(t *testing.T, src io.Reader, path string, opts ...bbs.Option) bbs.BBS {
+	t.Helper()
+	got := bytes.Buffer{}
+	b, err := bbs.HTML(&got, src, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("HTML() does not match %s\ngot:\n%s", path, got.String())
+	}
+	return b
+}
+
+// TestGolden renders the synthetic BBS art in testdata, see testdata/README.md,
+// and compares it to the golden HTML files.
+// The art is CP-437 encoded and uses a mix of line endings, controls, long lines and SAUCE records.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		want bbs.BBS
+	}{
+		{"celerity.txt", bbs.Celerity},
+		{"pcboard.pcb", bbs.PCBoard},
+		{"pcboard_ansi.pcb", bbs.PCBoard},
+		{"renegade.txt", bbs.Renegade},
+		{"telegard.txt", bbs.Telegard},
+		{"wildcat.txt", bbs.Wildcat},
+		{"wwivhash.txt", bbs.WWIVHash},
+		{"wwivheart.txt", bbs.WWIVHeart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("testdata", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := transform.NewReader(bytes.NewReader(src), charmap.CodePage437.NewDecoder())
+			path := filepath.Join("testdata", strings.TrimSuffix(tt.name, filepath.Ext(tt.name))+".golden.html")
+			if b := golden(t, r, path); b != tt.want {
+				t.Errorf("HTML() = %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+// TestGolden_examples renders the embedded example files of every format
+// and compares them to the golden HTML files in testdata/examples.
+func TestGolden_examples(t *testing.T) {
+	tests := map[string]bbs.BBS{
+		"celerity.txt":  bbs.Celerity,
+		"hello.pcb":     bbs.PCBoard,
+		"pcboard.txt":   bbs.PCBoard,
+		"renegade.asc":  bbs.Renegade,
+		"telegard.txt":  bbs.Telegard,
+		"wildcat.bbs":   bbs.Wildcat,
+		"wwivhash.txt":  bbs.WWIVHash,
+		"wwivheart.msg": bbs.WWIVHeart,
+	}
+	examples, err := os.ReadDir(filepath.Join("static", "examples"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != len(tests) {
+		t.Errorf("found %d example files, want %d, add the new files to the test", len(examples), len(tests))
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("static", "examples", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", "examples", name+".golden.html")
+			if b := golden(t, bytes.NewReader(src), path, bbs.WithCodepage(charmap.CodePage437)); b != want {
+				t.Errorf("HTML() = %v, want %v", b, want)
+			}
+		})
+	}
+}
+
+// TestGolden_encoding finds the same format in the raw CP-437 and the decoded UTF-8 fixtures.
+func TestGolden_encoding(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "*.[pt][cx][bt]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := bbs.Find(bytes.NewReader(src))
+		decoded := bbs.Find(transform.NewReader(bytes.NewReader(src), charmap.CodePage437.NewDecoder()))
+		if raw != decoded || !raw.Valid() {
+			t.Errorf("Find() of %s = %v raw and %v decoded, want the same format", name, raw, decoded)
+		}
+	}
+}
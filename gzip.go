@@ -0,0 +1,59 @@
+package bbs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// gzipMagic is the signature of the gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// WithGzip sets whether the gzip compressed readers are decompressed, the default is true.
+// Archives of BBS art often store the text files as .gz, so the readers of [HTML], [Fields],
+// [Runs], [Document] and [RenderMulti] that begin with the gzip signature are decompressed
+// before the detection, while all other readers are unchanged. The [WithMaxSize] limit
+// applies to the decompressed bytes, and a corrupt gzip stream is returned as an error.
+// Disable the option to read the compressed bytes as they are.
+func WithGzip(enabled bool) Option {
+	return func(c *config) {
+		c.noGzip = !enabled
+	}
+}
+
+// gunzip returns a reader of the decompressed r when r begins with the gzip signature,
+// otherwise it returns a reader of r unchanged. An io.ReadSeeker is returned to its
+// current offset after reading the signature, so it remains an io.ReadSeeker.
+func (c config) gunzip(r io.Reader) (io.Reader, error) {
+	if c.noGzip || r == nil {
+		return r, nil
+	}
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			magic := make([]byte, len(gzipMagic))
+			n, _ := io.ReadFull(rs, magic)
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(magic[:n], gzipMagic) {
+				return rs, nil
+			}
+			return newGzip(rs)
+		}
+	}
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	return newGzip(br)
+}
+
+// newGzip returns a gzip reader of r.
+func newGzip(r io.Reader) (io.Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return zr, nil
+}
@@ -0,0 +1,103 @@
+package bbs_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/bengarrett/bbs"
+)
+
+// compress returns the gzip compressed s.
+func compress(t *testing.T, s string) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWithGzip(t *testing.T) {
+	const src = "@X1FHello\n@X0Eworld"
+	want := bytes.Buffer{}
+	if _, err := bbs.HTML(&want, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	gz := compress(t, src)
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{"seeker", bytes.NewReader(gz)},
+		{"reader", iotest.OneByteReader(bytes.NewReader(gz))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			find, err := bbs.HTML(&buf, tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if find != bbs.PCBoard {
+				t.Errorf("HTML() = %s, want %s", find, bbs.PCBoard)
+			}
+			if got := buf.String(); got != want.String() {
+				t.Errorf("HTML() = %q, want %q", got, want.String())
+			}
+		})
+	}
+	t.Run("plain", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		if _, err := bbs.HTML(&buf, iotest.OneByteReader(strings.NewReader(src))); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want.String() {
+			t.Errorf("HTML() = %q, want %q", got, want.String())
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		fields, _, _ := bbs.Fields(bytes.NewReader(gz), bbs.WithGzip(false))
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "\x1f\x8b") {
+			t.Errorf("Fields() = %q, want the compressed bytes", fields)
+		}
+	})
+	t.Run("runs", func(t *testing.T) {
+		runs, b, err := bbs.Runs(bytes.NewReader(gz))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != bbs.PCBoard || len(runs) != 2 || runs[1].Text != "world" {
+			t.Errorf("Runs() = %v, %s", runs, b)
+		}
+	})
+	t.Run("document", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		if _, err := bbs.Document(&buf, bytes.NewReader(gz)); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "<title>Hello</title>") {
+			t.Errorf("Document() = %q, want the title of the decompressed text", buf.String())
+		}
+	})
+	t.Run("max size", func(t *testing.T) {
+		_, err := bbs.HTML(&bytes.Buffer{}, bytes.NewReader(gz), bbs.WithMaxSize(8))
+		if !errors.Is(err, bbs.ErrSize) {
+			t.Errorf("HTML() error = %v, want %v", err, bbs.ErrSize)
+		}
+	})
+	t.Run("corrupt", func(t *testing.T) {
+		_, err := bbs.HTML(&bytes.Buffer{}, bytes.NewReader([]byte{0x1f, 0x8b, 0, 0}))
+		if err == nil {
+			t.Error("HTML() error = nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package bbs
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// ContentHash returns the hexadecimal SHA-256 hash of the visible text and its colors in src,
+// so the cosmetically identical files have the same hash, for finding the duplicates of an archive.
+// The SAUCE record and a binary tail are removed, see [TrimSAUCE] and [TrimBinaryTail],
+// the line endings are normalized, and the adjacent runs of text with the same colors are joined,
+// so the redundant color codes, such as "@X07Hi" and "@X07H@X07i", do not change the hash.
+// ANSI or an invalid BBS returns an empty string.
+func ContentHash(src []byte, b BBS) string {
+	runs, err := b.Runs(TrimBinaryTail(TrimSAUCE(src)))
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	fg, bg, text := -1, -1, []byte{}
+	write := func() {
+		if len(text) == 0 {
+			return
+		}
+		var p [3 * binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(p[:], uint64(fg))
+		n += binary.PutUvarint(p[n:], uint64(bg))
+		n += binary.PutUvarint(p[n:], uint64(len(text)))
+		h.Write(p[:n])
+		h.Write(text)
+	}
+	for _, r := range runs {
+		if r.Text == "" {
+			continue
+		}
+		if r.Foreground != fg || r.Background != bg {
+			write()
+			fg, bg, text = r.Foreground, r.Background, text[:0]
+		}
+		text = append(text, r.Text...)
+	}
+	write()
+	return hex.EncodeToString(h.Sum(nil))
+}
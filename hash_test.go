@@ -0,0 +1,53 @@
+package bbs_test
+
+import (
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestContentHash(t *testing.T) {
+	sauce := func(s string) []byte {
+		p := make([]byte, 128)
+		copy(p, "SAUCE00")
+		return append([]byte(s+"\x1a"), p...)
+	}
+	want := bbs.ContentHash([]byte("@X1FHello\n@X07world"), bbs.PCBoard)
+	if len(want) != 64 {
+		t.Fatalf("ContentHash() = %q, want a SHA-256 hash", want)
+	}
+	same := []struct {
+		name string
+		src  []byte
+	}{
+		{"crlf", []byte("@X1FHello\r\n@X07world")},
+		{"redundant", []byte("@X1FHel@X1Flo\n@X07wor@X07ld")},
+		{"sauce", sauce("@X1FHello\n@X07world")},
+		{"binary tail", []byte("@X1FHello\n@X07world" + string(make([]byte, 100)))},
+	}
+	for _, tt := range same {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.ContentHash(tt.src, bbs.PCBoard); got != want {
+				t.Errorf("ContentHash() = %q, want %q", got, want)
+			}
+		})
+	}
+	differ := []struct {
+		name string
+		src  []byte
+	}{
+		{"color", []byte("@X1EHello\n@X07world")},
+		{"text", []byte("@X1FHello\n@X07World")},
+		{"moved code", []byte("@X1FHello@X07\nworld")},
+	}
+	for _, tt := range differ {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.ContentHash(tt.src, bbs.PCBoard); got == want {
+				t.Errorf("ContentHash() = %q, want a different hash", got)
+			}
+		})
+	}
+	if got := bbs.ContentHash([]byte("\x1b[0mHello"), bbs.ANSI); got != "" {
+		t.Errorf("ContentHash() ANSI = %q, want an empty string", got)
+	}
+}
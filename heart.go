@@ -0,0 +1,80 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrHeart is returned when the WWIV heart introducer is not a control character that is free to use.
+var ErrHeart = errors.New("heart introducer is not a free control character")
+
+// etxHeart is the default introducer of the WWIV heart color codes, the CP-437 ETX control.
+const etxHeart = 0x03
+
+// WithHeart sets the introducer byte of the WWIV heart color codes, for the odd exports of the boards
+// and the tools that substituted a different control for the ETX (0x03) character, such as 0x04.
+// Each introducer that is followed by a digit is read as the ETX, so the codes are detected and
+// rendered as WWIVHeart. The default, or an introducer of 0, is the ETX.
+//
+// The introducer must be a control character, as the printable text and the CP-437 high bytes
+// are the content of the art. The tab, newline, form feed, carriage return, end-of-file and escape
+// controls are also an [ErrHeart], as are the introducers of the other formats, such as | @ ` and #.
+//
+// The option applies to the renderers and the readers of [HTML], [Fields], [Runs], [Document] and [RenderMulti],
+// where the introducers are only replaced when the source is WWIVHeart or no other format is found.
+// [Find] and the other functions without options only detect the ETX and the decoded ♥ glyph.
+func WithHeart(introducer byte) Option {
+	return func(c *config) {
+		c.heart = introducer
+	}
+}
+
+// validHeart reports whether the introducer can be used by WithHeart.
+func validHeart(introducer byte) bool {
+	const tab, lf, ff, cr, sub, esc, del = '\t', '\n', '\f', '\r', 0x1a, 0x1b, 0x7f
+	switch introducer {
+	case tab, lf, ff, cr, sub, esc:
+		return false
+	}
+	return introducer < ' ' || introducer == del
+}
+
+// heartAt returns the index of the first WithHeart introducer in src that is followed by a digit,
+// or -1 when there is none or the introducer is the default.
+func (c config) heartAt(src []byte) int {
+	if c.heart == 0 || c.heart == etxHeart || !validHeart(c.heart) {
+		return -1
+	}
+	for i := 0; i < len(src)-1; i++ {
+		if src[i] == c.heart && src[i+1] >= '0' && src[i+1] <= '9' {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceHearts replaces the WithHeart introducers of src that are followed by a digit with the ETX,
+// and reports whether any introducer was replaced. The src is modified.
+func (c config) replaceHearts(src []byte) bool {
+	i := c.heartAt(src)
+	if i < 0 {
+		return false
+	}
+	for ; i < len(src)-1; i++ {
+		if src[i] == c.heart && src[i+1] >= '0' && src[i+1] <= '9' {
+			src[i] = etxHeart
+		}
+	}
+	return true
+}
+
+// hearts returns a copy of src with the WithHeart introducers replaced by the ETX,
+// or src unchanged when there are no introducers to replace, so src is never modified.
+func (c config) hearts(src []byte) []byte {
+	if c.heartAt(src) < 0 {
+		return src
+	}
+	p := bytes.Clone(src)
+	c.replaceHearts(p)
+	return p
+}
@@ -0,0 +1,81 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestWithHeart(t *testing.T) {
+	const src, etx = "Hello \x041world\x04", "Hello \x031world\x04"
+	want := bytes.Buffer{}
+	if err := bbs.WWIVHeart.HTML(&want, []byte(etx)); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("reader", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		find, err := bbs.HTML(&buf, strings.NewReader(src), bbs.WithHeart(0x04))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if find != bbs.WWIVHeart {
+			t.Errorf("HTML() = %s, want %s", find, bbs.WWIVHeart)
+		}
+		if got := buf.String(); got != want.String() {
+			t.Errorf("HTML() = %q, want %q", got, want.String())
+		}
+	})
+	t.Run("bytes", func(t *testing.T) {
+		p := []byte(src)
+		buf := bytes.Buffer{}
+		if err := bbs.WWIVHeart.HTML(&buf, p, bbs.WithHeart(0x04)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want.String() {
+			t.Errorf("HTML() = %q, want %q", got, want.String())
+		}
+		if string(p) != src {
+			t.Errorf("HTML() modified src to %q", p)
+		}
+	})
+	t.Run("runs", func(t *testing.T) {
+		runs, err := bbs.WWIVHeart.Runs([]byte(src), bbs.WithHeart(0x04))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) != 2 || runs[1].Foreground != 1 || runs[1].Text != "world\x04" {
+			t.Errorf("Runs() = %+v", runs)
+		}
+	})
+	t.Run("default", func(t *testing.T) {
+		_, err := bbs.HTML(&bytes.Buffer{}, strings.NewReader(src))
+		if !errors.Is(err, bbs.ErrNone) {
+			t.Errorf("HTML() error = %v, want %v", err, bbs.ErrNone)
+		}
+	})
+	t.Run("other format", func(t *testing.T) {
+		fields, b, err := bbs.Fields(strings.NewReader("@X1FHi \x041"), bbs.WithHeart(0x04))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != bbs.PCBoard || len(fields) != 1 || fields[0] != "1FHi \x041" {
+			t.Errorf("Fields() = %q, %s, want the introducer kept in the PCBoard text", fields, b)
+		}
+	})
+	for _, c := range []byte{'1', '|', '@', '`', '#', 'A', ' ', 0x80, '\n', '\t', 0x1a, 0x1b} {
+		t.Run(fmt.Sprintf("invalid %#x", c), func(t *testing.T) {
+			_, err := bbs.HTML(&bytes.Buffer{}, strings.NewReader(src), bbs.WithHeart(c))
+			if !errors.Is(err, bbs.ErrHeart) {
+				t.Errorf("HTML() error = %v, want %v", err, bbs.ErrHeart)
+			}
+			_, _, err = bbs.Fields(strings.NewReader(src), bbs.WithHeart(c))
+			if !errors.Is(err, bbs.ErrHeart) {
+				t.Errorf("Fields() error = %v, want %v", err, bbs.ErrHeart)
+			}
+		})
+	}
+}
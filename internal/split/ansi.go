@@ -0,0 +1,164 @@
+package split
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PCBoardANSIRe is a regular expression to match either a PCBoard BBS color code
+// or an ANSI control sequence introducer (CSI) sequence.
+const PCBoardANSIRe string = `(?i:@X([0-9A-F][0-9A-F]))|\x1b\[([0-9;?]*)[ -/]*([@-~])`
+
+// ANSIControlsRe is a regular expression to match the ANSI sequences that are not displayed.
+// In order, these are the ANSI music sequences terminated by the shift out control,
+// the control sequence introducer (CSI) sequences other than select graphic rendition (SGR),
+// such as the cursor movements, and the two character escape sequences, such as the ESC 7 cursor save.
+const ANSIControlsRe string = `\x1b\[[MN][^\x0e\x1b]*\x0e|\x1b\[[0-9;?]*[ -/]*[@-ln-~]|\x1b[^\[]`
+
+// Compiled regular expressions of the ANSI sequences.
+var (
+	ansiControlsRe = regexp.MustCompile(ANSIControlsRe)
+	pcboardANSIRe  = regexp.MustCompile(PCBoardANSIRe)
+)
+
+// StripANSIControls removes the ANSI music, cursor and other non-display sequences from src.
+// The ANSI color sequences are kept.
+func StripANSIControls(src []byte) []byte {
+	return ansiControlsRe.ReplaceAll(src, nil)
+}
+
+// ansiToCGA maps the ANSI color order of black, red, green, yellow, blue,
+// magenta, cyan, white, to the CGA color order used by the PCBoard codes.
+var ansiToCGA = [8]int{0, 4, 2, 6, 1, 5, 3, 7}
+
+// sgr is the graphic rendition state shared by the PCBoard and ANSI codes.
+type sgr struct {
+	fg, bg      int // fg and bg are the CGA color values between 0 and 7.
+	bold, blink bool
+}
+
+func (s *sgr) reset() {
+	*s = sgr{fg: 7}
+}
+
+// pcboard applies a PCBoard background and foreground hexadecimal color value.
+func (s *sgr) pcboard(hex string) {
+	n, err := strconv.ParseUint(hex, 16, 8)
+	if err != nil {
+		return
+	}
+	const bright = 8
+	bg, fg := int(n>>4), int(n&0xf)
+	s.bg, s.blink = bg&7, bg >= bright
+	s.fg, s.bold = fg&7, fg >= bright
+}
+
+// ansi applies the semicolon separated parameters of an ANSI select graphic rendition sequence.
+func (s *sgr) ansi(params string) {
+	for _, p := range strings.Split(params, ";") {
+		n, err := strconv.Atoi(p)
+		if p == "" {
+			n, err = 0, nil
+		}
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			s.reset()
+		case n == 1:
+			s.bold = true
+		case n == 5:
+			s.blink = true
+		case n == 22:
+			s.bold = false
+		case n == 25:
+			s.blink = false
+		case n >= 30 && n <= 37:
+			s.fg = ansiToCGA[n-30]
+		case n == 39:
+			s.fg = 7
+		case n >= 40 && n <= 47:
+			s.bg = ansiToCGA[n-40]
+		case n == 49:
+			s.bg = 0
+		case n >= 90 && n <= 97:
+			s.fg, s.bold = ansiToCGA[n-90], true
+		case n >= 100 && n <= 107:
+			s.bg, s.blink = ansiToCGA[n-100], true
+		}
+	}
+}
+
+// classes returns the PCBoard hexadecimal background and foreground values of the state.
+func (s sgr) classes() (string, string) {
+	bg, fg := s.bg, s.fg
+	if s.blink {
+		bg += 8
+	}
+	if s.bold {
+		fg += 8
+	}
+	return fmt.Sprintf("%X", bg), fmt.Sprintf("%X", fg)
+}
+
+// PCBoardANSIHTML parses the string for both PCBoard BBS color codes and ANSI
+// select graphic rendition sequences to apply a HTML template.
+// All other ANSI sequences, such as music and cursor movements, are removed.
+func PCBoardANSIHTML(buf *bytes.Buffer, src []byte) error {
+	return Config{}.PCBoardANSIHTML(buf, src)
+}
+
+// PCBoardANSIHTML parses the string using the configuration, see the PCBoardANSIHTML function.
+func (c Config) PCBoardANSIHTML(buf *bytes.Buffer, src []byte) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	const idiomaticTpl = `<i class="{{.Prefix}}B{{.Background}} {{.Prefix}}F{{.Foreground}}"{{with .Title}} title="{{.}}"{{end}}>{{.Content}}</i>`
+	tmpl, err := c.Escape.parse("idomatic", idiomaticTpl)
+	if err != nil {
+		return err
+	}
+	n := buf.Len()
+	if err := c.pcboardANSI(buf, tmpl, StripANSIControls(src)); err != nil {
+		// a failed template can leave an unclosed element
+		buf.Truncate(n)
+		return err
+	}
+	return nil
+}
+
+// pcboardANSI writes the PCBoard codes and ANSI sequences of src to buf using the tmpl template.
+func (c Config) pcboardANSI(buf *bytes.Buffer, tmpl executor, src []byte) error {
+	locs := pcboardANSIRe.FindAllSubmatchIndex(src, -1)
+	if len(locs) == 0 {
+		return c.write(buf, tmpl, plainRun(src))
+	}
+	runs := plainRun(src[:locs[0][0]])
+	state := sgr{}
+	state.reset()
+	const sgrCmd = "m"
+	for i, loc := range locs {
+		switch {
+		case loc[2] >= 0:
+			state.pcboard(string(src[loc[2]:loc[3]]))
+		case string(src[loc[6]:loc[7]]) == sgrCmd:
+			state.ansi(string(src[loc[4]:loc[5]]))
+		}
+		end := len(src)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		content := src[loc[1]:end]
+		if len(content) == 0 {
+			continue
+		}
+		r := Run{Content: string(content)}
+		r.Background, r.Foreground = state.classes()
+		runs = append(runs, r)
+	}
+	return c.write(buf, tmpl, c.background(c.ice(runs), "0"))
+}
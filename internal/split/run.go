@@ -0,0 +1,342 @@
+package split
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// A Run is a substring of text with the color values of the color code that precedes it.
+type Run struct {
+	Background string // Background is the background color value in the notation of the format.
+	Foreground string // Foreground is the foreground color value in the notation of the format.
+	Content    string // Content is the text of the run.
+	Plain      bool   // Plain is text without colors, such as the text before the first color code.
+	Marker     string // Marker is the name of the structural code that precedes the run, if any.
+	Link       string // Link is the URL of the hyperlink that contains the run, if any.
+	Continued  bool   // Continued is a part of a split run that follows the part with the color code.
+}
+
+// Markers are the names of the structural codes that change the color state without a color value.
+const (
+	MarkerSwap    = "swap"    // MarkerSwap is the Celerity |S background swap.
+	MarkerControl = "control" // MarkerControl is the Celerity |! control.
+	MarkerReset   = "reset"   // MarkerReset is the PCBoard bare @X reset.
+)
+
+// plainRun returns the text as a plain run, or an empty slice if the text is empty.
+func plainRun(text []byte) []Run {
+	if len(text) == 0 {
+		return []Run{}
+	}
+	return []Run{{Content: string(text), Plain: true}}
+}
+
+// background returns the runs with the colors of their backgrounds replaced
+// by the default background color, when the background colors are not used.
+func (c Config) background(runs []Run, defaultBg string) []Run {
+	if !c.NoBack {
+		return runs
+	}
+	for i := range runs {
+		if !runs[i].Plain {
+			runs[i].Background = defaultBg
+		}
+	}
+	return runs
+}
+
+// IceBackground is the prefix of the PCBoard background color values 8 to 15
+// that are displayed as the iCE bright backgrounds instead of blinking,
+// so the @X81 code is rendered with the "PBI8 PF1" classes.
+const IceBackground = "I"
+
+// ice returns the PCBoard runs with the blinking background color values 8 to 15
+// replaced by the iCE bright background values, when the iCE colors are used.
+func (c Config) ice(runs []Run) []Run {
+	if !c.Ice {
+		return runs
+	}
+	for i, r := range runs {
+		const bright = "89ABCDEF"
+		if !r.Plain && len(r.Background) == 1 && strings.Contains(bright, r.Background) {
+			runs[i].Background = IceBackground + r.Background
+		}
+	}
+	return runs
+}
+
+// execute writes the runs to buf, the runs with colors use the tmpl template,
+// while the plain runs are written using the escaping policy.
+// On error, any partial output is discarded and buf is returned to its previous length.
+func (c Config) execute(buf *bytes.Buffer, tmpl executor, runs []Run) error {
+	n := buf.Len()
+	if err := c.write(buf, tmpl, runs); err != nil {
+		// a failed template can leave an unclosed element
+		buf.Truncate(n)
+		return err
+	}
+	return nil
+}
+
+// write writes the runs to buf, see execute.
+func (c Config) write(buf *bytes.Buffer, tmpl executor, runs []Run) error {
+	runs = c.Trailing(runs)
+	if c.Transform != nil {
+		for i, r := range runs {
+			if r.Content != "" {
+				runs[i].Content = string(c.Transform([]byte(r.Content)))
+			}
+		}
+	}
+	if c.Compact {
+		runs = compact(runs)
+	}
+	if c.Lines {
+		runs = lines(runs)
+	}
+	if c.Ends {
+		runs = ends(runs)
+	}
+	if c.Links {
+		runs = links(runs)
+	}
+	d := colorStr{Prefix: c.prefix()}
+	number, newline := 0, true
+	for _, r := range runs {
+		if c.Lines && newline && r.Content != "" {
+			// the line number is written before the elements, so it never inherits the colors
+			number++
+			if _, err := fmt.Fprintf(buf, `<span class="%sline">%d</span>`, c.prefix(), number); err != nil {
+				return err
+			}
+		}
+		if r.Content != "" {
+			newline = strings.HasSuffix(r.Content, "\n")
+		}
+		if r.Link == "" {
+			if err := c.run(buf, tmpl, &d, r); err != nil {
+				return err
+			}
+			continue
+		}
+		// the hyperlink contains the element, so a link that crosses a color change
+		// is written as an adjacent hyperlink for each element
+		href := r.Link
+		if c.Escape == EscapeHTML {
+			href = template.HTMLEscapeString(href)
+		}
+		if _, err := buf.WriteString(`<a href="` + href + `">`); err != nil {
+			return err
+		}
+		if err := c.run(buf, tmpl, &d, r); err != nil {
+			return err
+		}
+		if _, err := buf.WriteString("</a>"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run writes the run to buf, the run with colors uses the tmpl template and the d template data.
+func (c Config) run(buf *bytes.Buffer, tmpl executor, d *colorStr, r Run) error {
+	marker := ""
+	if c.Markers && r.Marker != "" {
+		marker = `<wbr data-bbs="` + r.Marker + `">`
+		if c.XHTML {
+			marker = `<wbr data-bbs="` + r.Marker + `" />`
+		}
+	}
+	if r.Plain {
+		// the marker of a code without content, or of a reset, is written outside of the elements
+		if _, err := buf.WriteString(marker); err != nil {
+			return err
+		}
+		return c.Escape.Write(buf, []byte(r.Content))
+	}
+	if c.Code != nil && !r.Continued {
+		// the label of the code is written before the element, so it does not have the colors
+		if code := c.Code(r.Background, r.Foreground); code != "" {
+			label := `<code class="` + c.prefix() + `code">` + template.HTMLEscapeString(code) + `</code>`
+			if _, err := buf.WriteString(label); err != nil {
+				return err
+			}
+		}
+	}
+	// the marker of a code with content is written inside of the element
+	d.Background, d.Foreground, d.Content = r.Background, r.Foreground, r.Content
+	if c.Remap != nil {
+		d.Foreground = c.Remap(r.Background, r.Foreground)
+	}
+	if c.Title != nil {
+		d.Title = c.Title(r.Background, r.Foreground)
+	}
+	d.Marker = template.HTML(marker) // the marker names are constants
+	return tmpl.Execute(buf, *d)
+}
+
+// lines returns the runs split after each newline, so every line begins with a new run.
+// The marker of a split run is kept by the first line.
+func lines(runs []Run) []Run {
+	res := make([]Run, 0, len(runs))
+	for _, r := range runs {
+		if !strings.Contains(r.Content, "\n") {
+			res = append(res, r)
+			continue
+		}
+		for i, line := range strings.SplitAfter(r.Content, "\n") {
+			if line == "" {
+				continue
+			}
+			l := r
+			l.Content = line
+			if i > 0 {
+				l.Marker, l.Continued = "", true
+			}
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// trailing returns the runs without the color codes at the end that have no content,
+// such as the reset of a file that ends with @X07, which would be written as empty elements.
+func trailing(runs []Run) []Run {
+	for len(runs) > 0 {
+		if r := runs[len(runs)-1]; r.Plain || r.Content != "" {
+			break
+		}
+		runs = runs[:len(runs)-1]
+	}
+	return runs
+}
+
+// Trailing returns the runs without the color codes at the end that have no content,
+// unless Keep is set, so the runs match the elements written by the HTML templates.
+func (c Config) Trailing(runs []Run) []Run {
+	if c.Keep {
+		return runs
+	}
+	return trailing(runs)
+}
+
+// ends returns the runs with each newline split into a plain run, so the newlines are written
+// outside of the color elements. The marker of a split run is kept by its first part.
+func ends(runs []Run) []Run {
+	res := make([]Run, 0, len(runs))
+	for _, r := range runs {
+		if r.Plain || !strings.Contains(r.Content, "\n") {
+			res = append(res, r)
+			continue
+		}
+		marker, continued := r.Marker, r.Continued
+		for _, line := range strings.SplitAfter(r.Content, "\n") {
+			content, newline := strings.CutSuffix(line, "\n")
+			if content != "" {
+				l := r
+				l.Content, l.Marker, l.Continued = content, marker, continued
+				res = append(res, l)
+				marker, continued = "", true
+			}
+			if newline {
+				res = append(res, Run{Content: "\n", Plain: true, Marker: marker})
+				marker = ""
+			}
+		}
+	}
+	return res
+}
+
+// compact returns the runs with each space, tab and newline run of their joined content collapsed,
+// so a whitespace run that crosses a color change is collapsed the same as within a run.
+// A whitespace run that contains a newline becomes a single newline, otherwise a single space,
+// which is kept by the run that contains its first character. Runs left without content are removed,
+// while the runs that never had content, such as the markers, are kept.
+func compact(runs []Run) []Run {
+	contents := make([][]byte, len(runs))
+	// at is the run and position of the character that replaces the current whitespace run, if any
+	at, pos := -1, 0
+	for i, r := range runs {
+		for j := range len(r.Content) {
+			c := r.Content[j]
+			if c != ' ' && c != '\t' && c != '\n' {
+				at = -1
+				contents[i] = append(contents[i], c)
+				continue
+			}
+			if at < 0 {
+				at, pos = i, len(contents[i])
+				contents[i] = append(contents[i], ' ')
+			}
+			if c == '\n' {
+				contents[at][pos] = '\n'
+			}
+		}
+	}
+	res := make([]Run, 0, len(runs))
+	for i, r := range runs {
+		if r.Content != "" && len(contents[i]) == 0 {
+			continue
+		}
+		r.Content = string(contents[i])
+		res = append(res, r)
+	}
+	return res
+}
+
+// urlRe matches the bare http and https URLs of the text.
+var urlRe = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// links returns the runs split at the bare http and https URLs of their joined content,
+// and the Link of the runs that are a part of a URL. So a URL that crosses a color change
+// is a link of each run. The marker of a split run is kept by the first part.
+func links(runs []Run) []Run {
+	s := strings.Builder{}
+	for _, r := range runs {
+		s.WriteString(r.Content)
+	}
+	text := s.String()
+	locs := urlRe.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return runs
+	}
+	for _, loc := range locs {
+		// the trailing punctuation is usually the end of a sentence
+		loc[1] = loc[0] + len(strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?'\")]"))
+	}
+	res := make([]Run, 0, len(runs))
+	offset := 0
+	for _, r := range runs {
+		start, end := offset, offset+len(r.Content)
+		offset = end
+		parts := []Run{}
+		part := func(from, to int, link string) {
+			p := r
+			p.Content, p.Link = text[from:to], link
+			if len(parts) > 0 {
+				p.Marker, p.Continued = "", true
+			}
+			parts = append(parts, p)
+		}
+		pos := start
+		for _, loc := range locs {
+			if loc[1] <= pos || loc[0] >= end {
+				continue
+			}
+			if loc[0] > pos {
+				part(pos, loc[0], "")
+			}
+			to := min(loc[1], end)
+			part(max(loc[0], pos), to, text[loc[0]:loc[1]])
+			pos = to
+		}
+		if pos < end || len(parts) == 0 {
+			part(pos, end, "")
+		}
+		res = append(res, parts...)
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package split
+
+import (
+	"bytes"
+	"testing"
+	text "text/template"
+)
+
+func Test_execute(t *testing.T) {
+	// the template fails on the second run, after writing the start of an element
+	tmpl := text.Must(text.New("fail").Parse(
+		`<i class="{{.Foreground}}">{{if eq .Content "fail"}}{{template "missing"}}{{end}}{{.Content}}</i>`))
+	runs := []Run{
+		{Foreground: "1", Content: "Hello"},
+		{Content: " plain ", Plain: true},
+		{Foreground: "2", Content: "fail"},
+	}
+	const previous = "previous output"
+	buf := bytes.NewBufferString(previous)
+	if err := (Config{}).execute(buf, tmpl, runs); err == nil {
+		t.Fatal("execute() error = nil, want an error")
+	}
+	if buf.String() != previous {
+		t.Errorf("execute() buf = %q, want %q", buf.String(), previous)
+	}
+	buf = bytes.NewBufferString(previous)
+	if err := (Config{}).execute(buf, tmpl, runs[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if want := previous + `<i class="1">Hello</i> plain `; buf.String() != want {
+		t.Errorf("execute() buf = %q, want %q", buf.String(), want)
+	}
+}
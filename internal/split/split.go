@@ -4,102 +4,203 @@ import (
 	"bytes"
 	"errors"
 	"html/template"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	text "text/template"
 )
 
 var ErrBuff = errors.New("bytes buffer cannot be nil")
 
-// colorInt template data for integer based color codes.
-type colorInt struct {
-	Background int
-	Foreground int
-	Content    string
+// Escape is the escaping policy applied to the content of the color codes.
+// Each output format should use the policy that matches its document type,
+// so content is escaped exactly once.
+type Escape int
+
+const (
+	// EscapeHTML escapes the HTML special characters, it is used by the HTML output.
+	EscapeHTML Escape = iota
+	// EscapeNone writes the content as-is, it is used by the plain text and ANSI outputs.
+	EscapeNone
+)
+
+// executor is the shared method set of the html/template and text/template templates.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// parse parses the tpl text using the template package that applies the escaping policy.
+func (e Escape) parse(name, tpl string) (executor, error) {
+	if e == EscapeNone {
+		return text.New(name).Parse(tpl)
+	}
+	return template.New(name).Parse(tpl)
+}
+
+// Write writes p to w using the escaping policy.
+func (e Escape) Write(w io.Writer, p []byte) error {
+	if e == EscapeNone {
+		_, err := w.Write(p)
+		return err
+	}
+	template.HTMLEscape(w, p)
+	return nil
+}
+
+// Config is the configuration of the HTML templates.
+// The zero value escapes the HTML content and uses the default class prefix.
+type Config struct {
+	Escape  Escape // Escape is the escaping policy of the content.
+	Prefix  string // Prefix of the CSS color class names, an empty value uses Prefix.
+	Reset   bool   // Reset treats a bare PCBoard @X at a code position as a reset, see BareResets.
+	Inherit bool   // Inherit treats the PCBoard @X00 as no change, so its content keeps the current colors.
+	Markers bool   // Markers writes the swap, control and reset codes as zero-width <wbr> elements.
+	Lines   bool   // Lines prefixes each line with a line number that is outside of the color elements.
+	Ends    bool   // Ends writes the newlines outside of the color elements, so every line has its own elements.
+	Links   bool   // Links writes the bare http and https URLs of the content as hyperlinks.
+	NoBack  bool   // NoBack replaces the background colors with the default background color.
+	Compact bool   // Compact collapses the space, tab and newline runs of the content to a space or a newline.
+	XHTML   bool   // XHTML self-closes the void elements, such as <wbr />.
+	Keep    bool   // Keep writes the trailing codes without content as empty elements, instead of dropping them.
+	Ice     bool   // Ice writes the PCBoard backgrounds 8 to 15 as the iCE bright backgrounds instead of blinking.
+	// Remap returns the replacement of the foreground color of the background and foreground colors.
+	Remap func(background, foreground string) string
+	// Title returns the title attribute of the element of the background and foreground colors,
+	// an empty value or a nil Title writes no attribute.
+	Title func(background, foreground string) string
+	// Code returns the visible label of the color code that begins each element, which is written
+	// as a <code> element before it, an empty value or a nil Code writes no label.
+	Code func(background, foreground string) string
+	// Transform returns the replacement of the content of each run, it is applied before the escaping.
+	Transform func(content []byte) []byte
+}
+
+// Prefix is the default prefix of the CSS color class names.
+const Prefix = "P"
+
+func (c Config) prefix() string {
+	if c.Prefix == "" {
+		return Prefix
+	}
+	return c.Prefix
 }
 
 // colorStr template data for string based color codes.
 type colorStr struct {
+	Prefix     string
 	Background string
 	Foreground string
+	Marker     template.HTML
 	Content    string
+	Title      string
 }
 
 const (
-	// CelerityRe is a regular expression to match Celerity BBS color codes.
-	CelerityRe string = `\|(k|b|g|c|r|m|y|w|d|B|G|C|R|M|Y|W|S)`
+	// CelerityRe is a regular expression to match Celerity BBS color and control codes.
+	CelerityRe string = `\|(k|b|g|c|r|m|y|w|d|B|G|C|R|M|Y|W|S|!)`
 
 	// PCBoardRe is a case-insensitive, regular expression to match PCBoard BBS color codes.
 	PCBoardRe string = "(?i)@X([0-9A-F][0-9A-F])"
 
 	// VBarsRe is a regular expression to match Renegade BBS color codes.
-	VBarsRe string = `\|(0[0-9]|1[1-9]|2[0-3])`
+	VBarsRe string = `\|(0[0-9]|1[0-9]|2[0-3])`
+)
+
+// Compiled regular expressions of the color codes.
+var (
+	celerityRe = regexp.MustCompile(CelerityRe)
+	pcboardRe  = regexp.MustCompile(PCBoardRe)
+	vbarsRe    = regexp.MustCompile(VBarsRe)
 )
 
 // VBars slices a string into substrings separated by "|" vertical bar codes.
 // The first two bytes of each substring will contain a colour value.
+// A "|" that is not followed by a valid code is literal text, such as "||", "| " or "|99",
+// the same rule is used by Celerity.
 // Vertical bar codes are used by Renegade, WWIV hash and WWIV heart formats.
 // An empty slice is returned when no valid bar code values exists.
 func VBars(src []byte) []string {
-	const sep rune = 65535
-	re := regexp.MustCompile(VBarsRe)
-	repl := string(sep) + "$1"
-	res := re.ReplaceAll(src, []byte(repl))
-	if !bytes.ContainsRune(res, sep) {
-		return nil
-	}
-
-	spl := bytes.Split(res, []byte(string(sep)))
-	app := []string{}
-	for _, val := range spl {
-		if len(val) == 0 {
-			continue
+	return fields(vbarsRe, src)
+}
+
+// fields splits src before the color value of each color code matched by re,
+// which is the first submatch of re, so each field begins with its color value.
+// The text before the first code is also a field, unless it is empty.
+// The src is converted to a single string, and the fields are its substrings.
+func fields(re *regexp.Regexp, src []byte) []string {
+	locs := re.FindAllSubmatchIndex(src, -1)
+	if len(locs) == 0 {
+		return []string{}
+	}
+	s := string(src)
+	res := make([]string, 0, len(locs)+1)
+	if locs[0][0] > 0 {
+		res = append(res, s[:locs[0][0]])
+	}
+	for i, loc := range locs {
+		end := len(s)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
 		}
-		app = append(app, string(val))
+		res = append(res, s[loc[2]:end])
 	}
-	return app
+	return res
 }
 
 // VBarsHTML parses the string for BBS color codes that use
 // vertical bar prefixes to apply a HTML template.
 func VBarsHTML(buf *bytes.Buffer, src []byte) error {
+	return Config{}.VBarsHTML(buf, src)
+}
+
+// VBarsHTML parses the string using the configuration, see the VBarsHTML function.
+func (c Config) VBarsHTML(buf *bytes.Buffer, src []byte) error {
 	if buf == nil {
 		return ErrBuff
 	}
-	const idiomaticTpl = `<i class="P{{.Background}} P{{.Foreground}}">{{.Content}}</i>`
-	tmpl, err := template.New("idomatic").Parse(idiomaticTpl)
+	const idiomaticTpl = `<i class="{{.Prefix}}{{.Background}} {{.Prefix}}{{.Foreground}}"{{with .Title}} title="{{.}}"{{end}}>{{.Marker}}{{.Content}}</i>`
+	tmpl, err := c.Escape.parse("idomatic", idiomaticTpl)
 	if err != nil {
 		return err
 	}
+	return c.execute(buf, tmpl, c.VBarsRuns(src))
+}
 
-	d := colorInt{
-		Foreground: 0,
-		Background: 0,
-		Content:    "",
-	}
-	bars := VBars(src)
-	if len(bars) == 0 {
-		_, err := buf.Write(src)
-		return err
-	}
-
-	for _, color := range bars {
+// VBarsRuns returns the runs of text and their colors using the configuration,
+// the colors are the decimal values of the vertical bar codes.
+func (c Config) VBarsRuns(src []byte) []Run {
+	fg, bg := 0, 0
+	plain, src := leading(src, vbarsRe)
+	runs := plainRun(plain)
+	for _, color := range VBars(src) {
 		n, err := strconv.Atoi(color[0:2])
 		if err != nil {
 			continue
 		}
 		if barForeground(n) {
-			d.Foreground = n
+			fg = n
 		}
 		if barBackground(n) {
-			d.Background = n
-		}
-		d.Content = color[2:]
-		if err := tmpl.Execute(buf, d); err != nil {
-			return err
+			bg = n
 		}
+		runs = append(runs, Run{
+			Background: strconv.Itoa(bg),
+			Foreground: strconv.Itoa(fg),
+			Content:    color[2:],
+		})
 	}
-	return nil
+	return c.background(runs, "0")
+}
+
+// leading returns the text in src that precedes the first color code matched by re,
+// and src from the first color code, or nil if there are no color codes.
+func leading(src []byte, re *regexp.Regexp) ([]byte, []byte) {
+	loc := re.FindIndex(src)
+	if loc == nil {
+		return src, nil
+	}
+	return src[:loc[0]], src[loc[0]:]
 }
 
 func barBackground(n int) bool {
@@ -127,124 +228,180 @@ func barForeground(n int) bool {
 // Celerity slices a string into substrings separated by "|" vertical bar codes.
 // The first byte of each substring will contain a Celerity colour value,
 // that are comprised of a single, alphabetic character.
+// A "|" that is not followed by a valid code is literal text, such as "||", "| " or "|x",
+// the same rule is used by VBars.
 // An empty slice is returned when no valid Celerity code values exists.
 func Celerity(src []byte) []string {
-	// The format uses the vertical bar "|" followed by a case sensitive single alphabetic character.
-	const sep rune = 65535
-	re := regexp.MustCompile(CelerityRe)
-	repl := string(sep) + "$1"
-	res := re.ReplaceAll(src, []byte(repl))
-	if !bytes.ContainsRune(res, sep) {
-		return []string{}
-	}
-
-	spl := bytes.Split(res, []byte(string(sep)))
-	clean := []string{}
-	for _, val := range spl {
-		if len(val) == 0 {
-			continue
-		}
-		clean = append(clean, string(val))
-	}
-	return clean
+	return fields(celerityRe, src)
 }
 
 // CelerityHTML parses the string for the unique Celerity BBS color codes
 // to apply a HTML template.
 func CelerityHTML(buf *bytes.Buffer, src []byte) error {
+	return Config{}.CelerityHTML(buf, src)
+}
+
+// CelerityHTML parses the string using the configuration, see the CelerityHTML function.
+func (c Config) CelerityHTML(buf *bytes.Buffer, src []byte) error {
 	if buf == nil {
 		return ErrBuff
 	}
-	const idiomaticTpl, swapCmd = `<i class="PB{{.Background}} PF{{.Foreground}}">{{.Content}}</i>`, "S"
-	tmpl, err := template.New("idomatic").Parse(idiomaticTpl)
+	const idiomaticTpl = `<i class="{{.Prefix}}B{{.Background}} {{.Prefix}}F{{.Foreground}}"{{with .Title}} title="{{.}}"{{end}}>{{.Marker}}{{.Content}}</i>`
+	tmpl, err := c.Escape.parse("idomatic", idiomaticTpl)
 	if err != nil {
 		return err
 	}
+	return c.execute(buf, tmpl, c.CelerityRuns(src))
+}
 
+// CelerityRuns returns the runs of text and their colors using the configuration,
+// the colors are the Celerity color letters.
+func (c Config) CelerityRuns(src []byte) []Run {
+	const swapCmd, controlCmd, defaultCmd = 'S', '!', 'd'
+	const defaultFg, defaultBg = "w", "k"
 	background := false
-	d := colorStr{
-		Foreground: "w",
-		Background: "k",
-		Content:    "",
-	}
-
-	bars := Celerity(src)
-	if len(bars) == 0 {
-		_, err := buf.Write(src)
-		return err
-	}
-	for _, color := range bars {
-		if color == swapCmd {
+	fg, bg := defaultFg, defaultBg
+	plain, src := leading(src, celerityRe)
+	runs := plainRun(plain)
+	for _, color := range Celerity(src) {
+		code := color[0]
+		switch code {
+		case swapCmd:
 			background = !background
-			continue
+		case controlCmd:
+		case defaultCmd:
+			// the default color resets the foreground, or the background after a swap
+			if !background {
+				fg = defaultFg
+			}
+			if background {
+				bg = defaultBg
+			}
+		default:
+			if !background {
+				fg = string(code)
+			}
+			if background {
+				bg = string(code)
+			}
 		}
-		if !background {
-			d.Foreground = string(color[0])
+		r := Run{Background: bg, Foreground: fg, Content: color[1:]}
+		switch code {
+		case swapCmd:
+			r.Marker = MarkerSwap
+		case controlCmd:
+			r.Marker = MarkerControl
 		}
-		if background {
-			d.Background = string(color[0])
-		}
-		d.Content = color[1:]
-		if err := tmpl.Execute(buf, d); err != nil {
-			return err
+		if r.Content == "" && r.Marker != "" {
+			// the swap and control codes are removed, but their content keeps the current colors
+			if c.Markers {
+				runs = append(runs, Run{Marker: r.Marker, Plain: true})
+			}
+			continue
 		}
+		runs = append(runs, r)
 	}
-	return nil
+	return c.background(runs, defaultBg)
 }
 
 // PCBoard slices a string into substrings separated by PCBoard @X codes.
 // The first two bytes of each substring will contain background
 // and foreground hex colour values.
+// An @X is only a code when it is immediately followed by two hex digits,
+// any other @X is literal content of the preceding code.
+// Adjacent codes without content between them each return a two byte substring.
 // An empty slice is returned when no valid @X code values exists.
 func PCBoard(src []byte) []string {
-	const sep rune = 65535
-	re := regexp.MustCompile(PCBoardRe)
-	repl := string(sep) + "$1"
-	res := re.ReplaceAll(src, []byte(repl))
-	if !bytes.ContainsRune(res, sep) {
-		return []string{}
-	}
-
-	spl := bytes.Split(res, []byte(string(sep)))
-	clean := []string{}
-	for _, val := range spl {
-		if len(val) == 0 {
-			continue
-		}
-		clean = append(clean, string(val))
-	}
-	return clean
+	return fields(pcboardRe, src)
 }
 
 // PCBoardHTML parses the string for the common PCBoard BBS color codes
 // to apply a HTML template.
 func PCBoardHTML(buf *bytes.Buffer, src []byte) error {
+	return Config{}.PCBoardHTML(buf, src)
+}
+
+// PCBoardHTML parses the string using the configuration, see the PCBoardHTML function.
+func (c Config) PCBoardHTML(buf *bytes.Buffer, src []byte) error {
 	if buf == nil {
 		return ErrBuff
 	}
-	const idiomaticTpl = `<i class="PB{{.Background}} PF{{.Foreground}}">{{.Content}}</i>`
-	tmpl, err := template.New("idomatic").Parse(idiomaticTpl)
+	const idiomaticTpl = `<i class="{{.Prefix}}B{{.Background}} {{.Prefix}}F{{.Foreground}}"{{with .Title}} title="{{.}}"{{end}}>{{.Marker}}{{.Content}}</i>`
+	tmpl, err := c.Escape.parse("idomatic", idiomaticTpl)
 	if err != nil {
 		return err
 	}
+	return c.execute(buf, tmpl, c.PCBoardRuns(src))
+}
 
-	d := colorStr{
-		Foreground: "",
-		Background: "",
-		Content:    "",
-	}
-	xcodes := PCBoard(src)
-	if len(xcodes) == 0 {
-		_, err := buf.Write(src)
-		return err
+// PCBoardRuns returns the runs of text and their colors using the configuration,
+// the colors are the uppercase hexadecimal values of the @X codes.
+func (c Config) PCBoardRuns(src []byte) []Run {
+	const defaultBg, defaultFg = "0", "7"
+	plain, src := leading(src, pcboardRe)
+	runs := plainRun(plain)
+	bg, fg := defaultBg, defaultFg
+	for _, color := range PCBoard(src) {
+		r := Run{
+			Background: strings.ToUpper(string(color[0])),
+			Foreground: strings.ToUpper(string(color[1])),
+			Content:    color[2:],
+		}
+		if c.Inherit && r.Background == "0" && r.Foreground == "0" {
+			// the transparent @X00 is an element of the current colors
+			r.Background, r.Foreground = bg, fg
+		}
+		bg, fg = r.Background, r.Foreground
+		if !c.Reset {
+			runs = append(runs, r)
+			continue
+		}
+		reset := BareResets(r.Content)
+		r.Content = reset[0]
+		runs = append(runs, r)
+		for _, s := range reset[1:] {
+			// the content following a reset uses the default colors
+			runs = append(runs, Run{Content: s, Plain: true, Marker: MarkerReset})
+			bg, fg = defaultBg, defaultFg
+		}
 	}
-	for _, color := range xcodes {
-		d.Background = strings.ToUpper(string(color[0]))
-		d.Foreground = strings.ToUpper(string(color[1]))
-		d.Content = color[2:]
-		if err := tmpl.Execute(buf, d); err != nil {
-			return err
+	return c.background(c.ice(runs), "0")
+}
+
+// BareResets slices the content of a PCBoard code around the bare @X resets,
+// the @X codes without the two hex digit color values, and removes the resets.
+// The first substring is the colored content, while the other substrings follow a reset.
+//
+// A bare @X is only a reset at a code position, where it is attached to the preceding content,
+// either at the start of the content or after a non-whitespace character, and where it is
+// followed by the end of the content, a whitespace character or another @ code.
+// So the @X found in prose, such as "the PCBoard @X code" or "use @Xcodes", remain as text.
+func BareResets(content string) []string {
+	const code = "@X"
+	res := []string{}
+	last := 0
+	for i := 0; i+len(code) <= len(content); i++ {
+		if !strings.EqualFold(content[i:i+len(code)], code) {
+			continue
+		}
+		if i > 0 && space(content[i-1]) {
+			continue
+		}
+		if next := i + len(code); next < len(content) && !space(content[next]) && content[next] != '@' {
+			continue
 		}
+		res = append(res, content[last:i])
+		last = i + len(code)
+		i = last - 1
 	}
-	return nil
+	return append(res, content[last:])
+}
+
+// space reports whether b is an ASCII whitespace character.
+func space(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
 }
@@ -3,7 +3,9 @@ package split_test
 import (
 	"bytes"
 	"fmt"
+	"html/template"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/bengarrett/bbs/internal/split"
@@ -23,7 +25,8 @@ func Test_VBars(t *testing.T) {
 		{"last", args{"|23"}, 1},
 		{"out of range", args{"|24"}, 0},
 		{"incomplete", args{"|2"}, 0},
-		{"multiples", args{"|01Hello|00 |10world"}, 2},
+		{"multiples", args{"|01Hello|00 |10world"}, 3},
+		{"light green", args{"|10"}, 1},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,6 +53,7 @@ func Test_Celerity(t *testing.T) {
 		{"first", args{"|k"}, 1},
 		{"last", args{"|W"}, 1},
 		{"swap", args{"|S"}, 1},
+		{"control", args{"|!"}, 1},
 		{"multiples", args{"|k|S|wHello world"}, 3},
 	}
 	for _, tt := range tests {
@@ -77,6 +81,11 @@ func Test_PCBoard(t *testing.T) {
 		{"out of range", args{"@XFG"}, 0},
 		{"incomplete", args{"@X0"}, 0},
 		{"multiples", args{"@X01Hello@X00 @X10world"}, 3},
+		{"adjacent", args{"@X07@X11"}, 2},
+		{"adjacent three", args{"@X07@X11@X1FHello"}, 3},
+		{"adjacent literal", args{"@X07@XHello"}, 1},
+		{"adjacent incomplete", args{"@X07@X1"}, 1},
+		{"adjacent out of range", args{"@X07@XFGHello@X11"}, 2},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -118,6 +127,23 @@ func Test_CelerityHTML(t *testing.T) {
 		},
 		{"false positive", args{"| Hello world |"}, "| Hello world |", false},
 		{"double bar", args{"||pipes"}, "||pipes", false},
+		{"control", args{"|!Hello"}, "<i class=\"PBk PFw\">Hello</i>", false},
+		{"default", args{"|RHello |dworld"}, "<i class=\"PBk PFR\">Hello </i><i class=\"PBk PFw\">world</i>", false},
+		{
+			"default background",
+			args{"|S|bHello |dworld"},
+			"<i class=\"PBb PFw\">Hello </i><i class=\"PBk PFw\">world</i>", false,
+		},
+		{
+			"default both",
+			args{"|Y|S|bHello |d|S|dworld"},
+			"<i class=\"PBk PFY\"></i><i class=\"PBb PFY\">Hello </i>" +
+				"<i class=\"PBk PFY\"></i><i class=\"PBk PFw\">world</i>", false,
+		},
+		{"leading", args{"Hello |Rworld"}, "Hello <i class=\"PBk PFR\">world</i>", false},
+		{"leading byte", args{"a|Rb"}, "a<i class=\"PBk PFR\">b</i>", false},
+		{"swap content", args{"|RA|SB|bC"}, "<i class=\"PBk PFR\">A</i><i class=\"PBk PFR\">B</i><i class=\"PBb PFR\">C</i>", false},
+		{"control state", args{"|R|!Hello|!"}, "<i class=\"PBk PFR\"></i><i class=\"PBk PFR\">Hello</i>", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -133,3 +159,172 @@ func Test_CelerityHTML(t *testing.T) {
 		})
 	}
 }
+
+func Test_Escape(t *testing.T) {
+	none := split.Config{Escape: split.EscapeNone}
+	tests := []struct {
+		name string
+		fn   func(*bytes.Buffer, []byte) error
+		src  string
+		want string
+	}{
+		{"html vbars", split.Config{}.VBarsHTML, "|07<b>A&B</b>", "<i class=\"P0 P7\">&lt;b&gt;A&amp;B&lt;/b&gt;</i>"},
+		{"none vbars", none.VBarsHTML, "|07<b>A&B</b>", "<i class=\"P0 P7\"><b>A&B</b></i>"},
+		{"html celerity", split.Config{}.CelerityHTML, "|w<b>A&B</b>", "<i class=\"PBk PFw\">&lt;b&gt;A&amp;B&lt;/b&gt;</i>"},
+		{"none celerity", none.CelerityHTML, "|w<b>A&B</b>", "<i class=\"PBk PFw\"><b>A&B</b></i>"},
+		{"html pcboard", split.Config{}.PCBoardHTML, "@X07<b>A&B</b>", "<i class=\"PB0 PF7\">&lt;b&gt;A&amp;B&lt;/b&gt;</i>"},
+		{"none pcboard", none.PCBoardHTML, "@X07<b>A&B</b>", "<i class=\"PB0 PF7\"><b>A&B</b></i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.fn(&got, []byte(tt.src)); err != nil {
+				t.Error(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Escape = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_EscapeNoCodes(t *testing.T) {
+	const src = "<b>Hello & world</b>"
+	tests := []struct {
+		name string
+		c    split.Config
+		want string
+	}{
+		{"html", split.Config{}, "&lt;b&gt;Hello &amp; world&lt;/b&gt;"},
+		{"none", split.Config{Escape: split.EscapeNone}, src},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.c.PCBoardHTML(&got, []byte(src)); err != nil {
+				t.Error(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Config.PCBoardHTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_PCBoardANSIHTML(t *testing.T) {
+	const esc = "\x1b["
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"string", "hello world", "hello world"},
+		{"pcboard", "@X07Hello", "<i class=\"PB0 PF7\">Hello</i>"},
+		{"ansi", esc + "1;31mHello", "<i class=\"PB0 PFC\">Hello</i>"},
+		{"leading", "Hi " + esc + "32mthere", "Hi <i class=\"PB0 PF2\">there</i>"},
+		{"reset", esc + "44mA" + esc + "0mB", "<i class=\"PB1 PF7\">A</i><i class=\"PB0 PF7\">B</i>"},
+		{"blink", esc + "5;47;30mA", "<i class=\"PBF PF0\">A</i>"},
+		{"cursor", esc + "2J" + esc + "10;1H@X1FA", "<i class=\"PB1 PFF\">A</i>"},
+		{
+			"mixed", "@CLS@@X0FBlue\n" + esc + "0;33mBrown @X4EYellow",
+			"@CLS@<i class=\"PB0 PFF\">Blue\n</i><i class=\"PB0 PF6\">Brown </i><i class=\"PB4 PFE\">Yellow</i>",
+		},
+		{"mixed state", "@X1F" + esc + "31mA", "<i class=\"PB1 PFC\">A</i>"},
+		{"music", "@X07A" + esc + "MF T120 C D E\x0eB", "<i class=\"PB0 PF7\">AB</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := split.PCBoardANSIHTML(&got, []byte(tt.s)); err != nil {
+				t.Error(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("PCBoardANSIHTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_BareResets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"empty", "", []string{""}},
+		{"none", "Hello world", []string{"Hello world"}},
+		{"reset", "Hello@X world", []string{"Hello", " world"}},
+		{"casing", "Hello@x world", []string{"Hello", " world"}},
+		{"start", "@X world", []string{"", " world"}},
+		{"end", "Hello@X", []string{"Hello", ""}},
+		{"code", "Hello@X@CLS@", []string{"Hello", "@CLS@"}},
+		{"multiple", "a@X b@X c", []string{"a", " b", " c"}},
+		{"prose", "the @X code", []string{"the @X code"}},
+		{"prose word", "@Xcodes", []string{"@Xcodes"}},
+		{"prose punctuation", "a red @X?", []string{"a red @X?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := split.BareResets(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BareResets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_bars is the shared test matrix of the literal vertical bar rule,
+// a "|" that is not followed by a valid code is literal text.
+func Test_bars(t *testing.T) {
+	type renderer struct {
+		name   string
+		fields func([]byte) []string
+		html   func(*bytes.Buffer, []byte) error
+		valid  string // valid is a valid code of the format
+		class  string // class is the HTML class of the valid code
+	}
+	renderers := []renderer{
+		{"celerity", split.Celerity, split.CelerityHTML, "|w", "PBk PFw"},
+		{"vbars", split.VBars, split.VBarsHTML, "|07", "P0 P7"},
+	}
+	literals := []string{"||", "| ", "|x", "|", "a|z", "|99"}
+	for _, r := range renderers {
+		for _, lit := range literals {
+			t.Run(r.name+" "+lit, func(t *testing.T) {
+				if got := r.fields([]byte(lit)); len(got) != 0 {
+					t.Errorf("%s() = %q, want none", r.name, got)
+				}
+				buf := bytes.Buffer{}
+				if err := r.html(&buf, []byte(lit)); err != nil {
+					t.Fatal(err)
+				}
+				if want := template.HTMLEscapeString(lit); buf.String() != want {
+					t.Errorf("%sHTML() = %q, want %q", r.name, buf.String(), want)
+				}
+				// the literal before a valid code is the content of the code
+				src := r.valid + "Hi" + lit + r.valid + "!"
+				if got := r.fields([]byte(src)); len(got) != 2 {
+					t.Errorf("%s() = %q, want 2 codes", r.name, got)
+				}
+				buf.Reset()
+				if err := r.html(&buf, []byte(src)); err != nil {
+					t.Fatal(err)
+				}
+				want := `<i class="` + r.class + `">Hi` + template.HTMLEscapeString(lit) + `</i>` +
+					`<i class="` + r.class + `">!</i>`
+				if buf.String() != want {
+					t.Errorf("%sHTML() = %q, want %q", r.name, buf.String(), want)
+				}
+			})
+		}
+		t.Run(r.name+" double bar code", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := r.html(&buf, []byte("|"+r.valid+"Hi")); err != nil {
+				t.Fatal(err)
+			}
+			if want := `|<i class="` + r.class + `">Hi</i>`; buf.String() != want {
+				t.Errorf("%sHTML() = %q, want %q", r.name, buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package bbs
+
+import (
+	"bytes"
+	"regexp"
+	"unicode/utf8"
+)
+
+// zeroWidth is the UTF-8 zero width space (U+200B) that breaks the color codes of the literal text.
+const zeroWidth = "\u200b"
+
+// anchored are the regular expressions of the BBS formats that only match at the start of the text.
+var anchored = func() []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(regexps))
+	for i, re := range regexps {
+		res[i] = regexp.MustCompile(`^(?:` + re.String() + `)`)
+	}
+	return res
+}()
+
+// Escape returns src with the color codes of the BBS format made literal, so the renderers
+// display the codes as text instead of colors, which stops the accidental colorization of user prose.
+// A zero width space (U+200B) is inserted after the introducer of each code, so "@X07"
+// becomes "@" U+200B "X07", which is invisible in a browser using the UTF-8 character set.
+//
+// The scheme is reversible using [Unescape]. An introducer followed by zero width spaces
+// and then the remainder of a code gains one more zero width space, so text that already
+// contains the escaped codes is restored unchanged. An invalid BBS returns src unchanged.
+func Escape(src []byte, b BBS) []byte {
+	return b.literal(src, true)
+}
+
+// Unescape returns src with the color codes made literal by [Escape] restored.
+// An introducer followed by zero width spaces and then the remainder of a code loses one zero width space.
+// An invalid BBS returns src unchanged.
+func Unescape(src []byte, b BBS) []byte {
+	return b.literal(src, false)
+}
+
+// literal returns src with a zero width space added to, or removed from,
+// the introducer of each color code of the format.
+func (b BBS) literal(src []byte, escape bool) []byte {
+	if !b.Valid() {
+		return src
+	}
+	// the longest code is an ANSI sequence, the other codes use less than eight bytes
+	const longest = 64
+	re := anchored[b]
+	intros := b.introducers()
+	res := make([]byte, 0, len(src))
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		res = append(res, src[i:i+size]...)
+		i += size
+		if !bytes.ContainsRune(intros, r) {
+			continue
+		}
+		j, n := i, 0
+		for bytes.HasPrefix(src[j:], []byte(zeroWidth)) {
+			j += len(zeroWidth)
+			n++
+		}
+		code := utf8.AppendRune(nil, r)
+		code = append(code, src[j:min(j+longest, len(src))]...)
+		if !re.Match(code) {
+			continue
+		}
+		switch {
+		case escape:
+			n++
+		case n > 0:
+			n--
+		}
+		res = append(res, bytes.Repeat([]byte(zeroWidth), n)...)
+		i = j
+	}
+	return res
+}
+
+// introducers returns the runes that begin the color codes of the format.
+func (b BBS) introducers() []byte {
+	p := b.Bytes()
+	if len(p) == 0 {
+		return nil
+	}
+	res := []byte{p[0]}
+	if b == WWIVHeart {
+		res = append(res, heart...)
+	}
+	return res
+}
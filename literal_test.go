@@ -0,0 +1,73 @@
+package bbs_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestEscape(t *testing.T) {
+	const zw = "\u200b"
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want string
+	}{
+		{"pcboard", "use @X07 or @x1F", bbs.PCBoard, "use @" + zw + "X07 or @" + zw + "x1F"},
+		{"not a code", "mail me @ X07 @Xno", bbs.PCBoard, "mail me @ X07 @Xno"},
+		{"celerity", "a|w b|z", bbs.Celerity, "a|" + zw + "w b|z"},
+		{"renegade", "|07|24", bbs.Renegade, "|" + zw + "07|24"},
+		{"telegard", "`07", bbs.Telegard, "`" + zw + "07"},
+		{"wildcat", "@0F@0F@", bbs.Wildcat, "@" + zw + "0F@" + zw + "0F@"},
+		{"wwiv hash", "|#1", bbs.WWIVHash, "|" + zw + "#1"},
+		{"wwiv heart", "\x031♥2", bbs.WWIVHeart, "\x03" + zw + "1♥" + zw + "2"},
+		{"escaped", "@" + zw + "X07", bbs.PCBoard, "@" + zw + zw + "X07"},
+		{"invalid", "@X07", bbs.BBS(-1), "@X07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bbs.Escape([]byte(tt.src), tt.b)
+			if string(got) != tt.want {
+				t.Errorf("Escape() = %q, want %q", got, tt.want)
+			}
+			if back := bbs.Unescape(got, tt.b); string(back) != tt.src {
+				t.Errorf("Unescape() = %q, want %q", back, tt.src)
+			}
+		})
+	}
+}
+
+func TestEscape_render(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.[pt][cx][bt]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := bbs.Find(bytes.NewReader(src))
+		if b == bbs.ANSI || !b.Valid() {
+			continue
+		}
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			p := bbs.Escape(src, b)
+			if back := bbs.Unescape(p, b); !bytes.Equal(back, src) {
+				t.Error("Unescape() did not restore the source")
+			}
+			buf := bytes.Buffer{}
+			if err := b.HTML(&buf, p); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "<i ") {
+				t.Errorf("BBS.HTML() of the escaped source contains color elements")
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package bbs_test
+
+// race reports whether the tests are built with the race detector, which adds allocations.
+const race = false
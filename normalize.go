@@ -0,0 +1,21 @@
+package bbs
+
+import "bytes"
+
+// Normalize returns src with every valid color code of the BBS format rewritten to its canonical form,
+// while the content is unchanged, so the exact matches and the duplicates of the files are reliable.
+// The canonical PCBoard and Telegard codes use an uppercase introducer and hexadecimal values,
+// for example @xaB becomes @XAB and `1f becomes `1F.
+//
+// The other formats only have canonical codes, such as the two digit Renegade |07 and
+// the uppercase Wildcat! @1F@, and are returned unchanged. The sequences that are not valid codes,
+// such as the single digit |7 or the lowercase @1f@, are content that is kept as text.
+// The WWIV hash and heart codes can be normalized using [ConvertWWIV].
+// ANSI or an invalid BBS returns src unchanged.
+func Normalize(src []byte, b BBS) []byte {
+	switch b {
+	case PCBoard, Telegard:
+		return b.Regexp().ReplaceAllFunc(src, bytes.ToUpper)
+	}
+	return src
+}
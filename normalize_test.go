@@ -0,0 +1,32 @@
+package bbs_test
+
+import (
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want string
+	}{
+		{"pcboard case", "@xaBHello @Xfeworld", bbs.PCBoard, "@XABHello @XFEworld"},
+		{"pcboard content", "@x07hello @xyz", bbs.PCBoard, "@X07hello @xyz"},
+		{"telegard case", "`1fhello `afworld", bbs.Telegard, "`1Fhello `AFworld"},
+		{"renegade", "|07Hello |7world |17", bbs.Renegade, "|07Hello |7world |17"},
+		{"wildcat", "@1F@Hello @1f@", bbs.Wildcat, "@1F@Hello @1f@"},
+		{"celerity", "|wHello |W", bbs.Celerity, "|wHello |W"},
+		{"ansi", "\x1b[1mHi", bbs.ANSI, "\x1b[1mHi"},
+		{"invalid", "@x07Hi", -1, "@x07Hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.Normalize([]byte(tt.src), tt.b); string(got) != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"regexp"
+	"sync"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// Option errors.
+var (
+	ErrPrefix     = errors.New("prefix is not a valid css class name")
+	ErrSize       = errors.New("source exceeds the maximum size")
+	ErrBrightness = errors.New("brightness factor is not a positive number")
+)
+
+// MaxSize is the default maximum number of bytes read from a reader, 16 MiB.
+const MaxSize int64 = 16 << 20
+
+// An Option configures the HTML rendering and the generated CSS.
+type Option func(*config)
+
+// config is the per-call configuration created from the options.
+type config struct {
+	prefix     string
+	theme      Theme
+	maxSize    int64
+	noEscape   bool
+	reset      bool
+	pageBreak  bool
+	markers    bool
+	bright     float64
+	pool       *sync.Pool
+	strictly   bool
+	lines      bool
+	links      bool
+	noBack     bool
+	contrast   float64
+	noEndReset bool
+	blink      Blink
+	xhtml      bool
+	codepage   encoding.Encoding
+	compact    bool
+	font       []byte
+	fontSet    bool
+	blankLines int
+	ends       bool
+	inherit    bool
+	transform  func([]byte) []byte
+	keep       bool
+	titles     bool
+	noGzip     bool
+	heart      byte
+	verbatim   bool
+}
+
+// newConfig returns the configuration of the options.
+func newConfig(opts ...Option) config {
+	c := config{
+		prefix:  split.Prefix,
+		maxSize: MaxSize,
+		bright:  1,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&c)
+		}
+	}
+	return c
+}
+
+// scratch returns an empty buffer from the pool, or a new buffer without a pool.
+func (c config) scratch() *bytes.Buffer {
+	if c.pool != nil {
+		if buf, ok := c.pool.Get().(*bytes.Buffer); ok && buf != nil {
+			buf.Reset()
+			return buf
+		}
+	}
+	return &bytes.Buffer{}
+}
+
+// release returns the buffer to the pool.
+func (c config) release(buf *bytes.Buffer) {
+	if c.pool != nil {
+		c.pool.Put(buf)
+	}
+}
+
+// classNameRe matches a valid CSS class name.
+var classNameRe = regexp.MustCompile(`^-?[_a-zA-Z][_a-zA-Z0-9-]*$`)
+
+// validate returns an error if the configuration is unusable.
+func (c config) validate() error {
+	if !classNameRe.MatchString(c.prefix) {
+		return ErrPrefix
+	}
+	if !c.theme.Valid() {
+		return ErrTheme
+	}
+	if !c.blink.Valid() {
+		return ErrBlink
+	}
+	if !(c.bright >= 0) || math.IsInf(c.bright, 0) {
+		return ErrBrightness
+	}
+	if c.contrast != 0 && !(c.contrast >= 1 && c.contrast <= 21) {
+		return ErrContrast
+	}
+	if c.font != nil && fontType(c.font) == "" {
+		return ErrFont
+	}
+	if !validHeart(c.heart) {
+		return ErrHeart
+	}
+	return nil
+}
+
+// split returns the configuration of the HTML templates.
+func (c config) split() split.Config {
+	e := split.EscapeHTML
+	if c.noEscape {
+		e = split.EscapeNone
+	}
+	return split.Config{
+		Escape:    e,
+		Prefix:    c.prefix,
+		Reset:     c.reset,
+		Inherit:   c.inherit,
+		Markers:   c.markers,
+		Lines:     c.lines,
+		Ends:      c.ends,
+		Links:     c.links,
+		NoBack:    c.noBack,
+		XHTML:     c.xhtml,
+		Compact:   c.compact,
+		Transform: c.transform,
+		Keep:      c.keep,
+	}
+}
+
+// keepTrailing writes the trailing codes without content as empty elements, instead of dropping them,
+// so the HTML of the codes alone can be trimmed from the HTML of the codes that precede content.
+func keepTrailing() Option {
+	return func(c *config) {
+		c.keep = true
+	}
+}
+
+// WithPrefix sets the prefix of the CSS color class names, the default is "P".
+// With a "bbs-" prefix, the PCBoard code @X07 is rendered with the
+// "bbs-B0 bbs-F7" classes instead of the "PB0 PF7" classes.
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithMaxSize sets the maximum number of bytes read from a reader, the default is [MaxSize].
+// Readers that exceed the limit return an [ErrSize] error, which protects servers that
+// render uploaded files from huge memory allocations. A size of 0 or less removes the limit.
+func WithMaxSize(size int64) Option {
+	return func(c *config) {
+		c.maxSize = size
+	}
+}
+
+// WithUnsafeNoEscape disables the HTML escaping of the content in the HTML renderers,
+// so content that is already escaped upstream is not escaped twice, such as &lt; becoming &amp;lt;.
+//
+// Warning: this is unsafe and must only be used with trusted input! Any markup in the
+// content is written as-is, so untrusted input can inject HTML and scripts, a cross-site
+// scripting (XSS) vulnerability. It also voids the safety contract of [BBS.TemplateHTML].
+func WithUnsafeNoEscape() Option {
+	return func(c *config) {
+		c.noEscape = true
+	}
+}
+
+// WithBareReset interprets a bare PCBoard @X, an @X without the two hex digit color values,
+// as a reset that closes the current color and renders the following text with the default colors.
+// It only applies to the PCBoard format, as some PCBoard dialects use the bare @X as an off switch.
+//
+// A bare @X that is found in prose is kept as text, so a reset must be attached to
+// the preceding colored text and followed by a whitespace, the end of the text or another @ code.
+// For example, the @X in "@X0FHello@X world" is a reset, while the @X in "the @X code" is text.
+func WithBareReset() Option {
+	return func(c *config) {
+		c.reset = true
+	}
+}
+
+// WithPageBreak renders the form feed (0x0C) screen breaks as <br> elements with the "page" class,
+// for example <br class="Ppage">, which can be styled to separate the screens.
+// Without the option the form feeds are removed, like the @CLS@ clear screen control.
+func WithPageBreak() Option {
+	return func(c *config) {
+		c.pageBreak = true
+	}
+}
+
+// WithMarkers writes the structural codes that change the color state without a color value
+// as zero-width <wbr> elements, instead of silently removing them. These are the Celerity |S swap
+// and |! control codes, and the PCBoard bare @X reset when using [WithBareReset].
+// For example, the Celerity |S is written as <wbr data-bbs="swap">.
+// This preserves the exact code structure of the source through a [FromHTML] round trip.
+func WithMarkers() Option {
+	return func(c *config) {
+		c.markers = true
+	}
+}
+
+// WithBrightness scales the palette colors used by the generated CSS by the factor,
+// for example 0.8 for a dimmer CRT look or 1.2 for a brighter look, the default is 1.
+// Each red, green and blue value is multiplied by the factor and clamped to 0-255.
+// A negative factor, or a factor that is not a finite number, returns an [ErrBrightness] error.
+func WithBrightness(factor float64) Option {
+	return func(c *config) {
+		c.bright = factor
+	}
+}
+
+// WithBufferPool reuses the *bytes.Buffer values of the pool to read the source of the functions
+// that take an io.Reader, such as [HTML] and [Fields], instead of allocating a new buffer for every call.
+// This reduces the garbage collection of a high-throughput server that renders many documents.
+// The buffers are reset before use and put back into the pool when the call returns,
+// while any values of the pool that are not a *bytes.Buffer are ignored.
+func WithBufferPool(pool *sync.Pool) Option {
+	return func(c *config) {
+		c.pool = pool
+	}
+}
+
+// WithStrict returns a [*ParseError] that wraps an [ErrCode] error for the first sequence that
+// looks like a color code but fails validation, such as the PCBoard @X0G or the Renegade |24,
+// instead of rendering the sequence as literal text. See [BBS.Diagnose] for all the sequences.
+func WithStrict() Option {
+	return func(c *config) {
+		c.strictly = true
+	}
+}
+
+// WithLineNumbers prefixes each line with its line number in a <span> element with the "line" class,
+// for example <span class="Pline">1</span>, which can be styled as a gutter for a code listing view.
+// The color elements are split at the newlines, so the line numbers are never within
+// an element and do not inherit the colors. The numbers count the LF newlines of the source.
+func WithLineNumbers() Option {
+	return func(c *config) {
+		c.lines = true
+	}
+}
+
+// WithLinks writes the bare http:// and https:// URLs in the content as <a> hyperlinks.
+// The hyperlinks contain the color elements, so a URL that crosses a color change is written
+// as adjacent hyperlinks with the same URL, one for each element, which keeps the HTML well-formed.
+// The trailing punctuation of a URL, such as the period that ends a sentence, is not linked.
+func WithLinks() Option {
+	return func(c *config) {
+		c.links = true
+	}
+}
+
+// WithoutBackground replaces all the background colors with the default black background,
+// while the foreground colors are kept. This is intended for printing on paper,
+// where the backgrounds waste ink and the light on dark text is unreadable.
+// It applies to all the formats, and to the colors returned by [BBS.Runs].
+func WithoutBackground() Option {
+	return func(c *config) {
+		c.noBack = true
+	}
+}
+
+// WithContrast replaces the foreground colors that have a contrast ratio with their background
+// below the ratio, such as dark grey on black, with the nearest palette color that is readable.
+// The ratio is the WCAG contrast ratio between 1 and 21, where 4.5 is the recommended minimum for text,
+// while a ratio outside of this range returns an [ErrContrast] error. Use [LowContrast] to report the pairs.
+func WithContrast(ratio float64) Option {
+	return func(c *config) {
+		c.contrast = ratio
+	}
+}
+
+// WithEndReset sets whether the [BBS.Terminal] output ends with the ANSI reset sequence, the default is true.
+// The reset stops the last color from bleeding into the text that follows the output in a terminal.
+// The HTML output always closes its elements, so it is not affected by the option.
+func WithEndReset(enabled bool) Option {
+	return func(c *config) {
+		c.noEndReset = !enabled
+	}
+}
+
+// WithBlink sets the display of the PCBoard, Telegard and Wildcat! background color values 8 to 15,
+// and of the ANSI blink attribute, the default is [BlinkAuto].
+// Blinking backgrounds use the "PB8" to "PBF" classes, while the iCE bright backgrounds
+// use the "PBI8" to "PBIF" classes, so the @X81 code is rendered with the "PBI8 PF1" classes.
+func WithBlink(mode Blink) Option {
+	return func(c *config) {
+		c.blink = mode
+	}
+}
+
+// WithXHTML writes the HTML as well-formed XHTML for the strict XML pipelines, such as EPUB documents.
+// The void elements of the [WithPageBreak] and [WithMarkers] options are self-closed,
+// for example <br class="Ppage" />, and the control characters that XML does not allow,
+// such as the SUB end-of-file marker, are removed from the text, while any invalid UTF-8
+// is replaced by the U+FFFD replacement character. The element and attribute
+// names are always lowercase, while the class names keep their case to match the CSS.
+func WithXHTML() Option {
+	return func(c *config) {
+		c.xhtml = true
+	}
+}
+
+// WithCodepage decodes the source from the legacy codepage to UTF-8 before it is rendered, so the
+// HTML contains Unicode text, for example charmap.CodePage437 of the golang.org/x/text/encoding/charmap
+// package decodes the box drawing and block characters of BBS art. The default is no decoding,
+// which is the source already decoded to UTF-8, see the Encoding section.
+//
+// The color codes are detected on the raw source, as are the SAUCE record and its iCE colors flag.
+func WithCodepage(e encoding.Encoding) Option {
+	return func(c *config) {
+		c.codepage = e
+	}
+}
+
+// decode returns src decoded from the codepage to UTF-8, or src when there is no codepage.
+func (c config) decode(src []byte) ([]byte, error) {
+	if c.codepage == nil {
+		return src, nil
+	}
+	p, _, err := transform.Bytes(c.codepage.NewDecoder(), src)
+	return p, err
+}
+
+// WithCompactWhitespace collapses the runs of spaces, tabs and newlines of the text to reduce the size
+// of the HTML, for embedding without a <pre> element where the columns of the text do not matter.
+// A run that contains a newline, such as the blank lines, becomes a single newline, otherwise it becomes
+// a single space. The runs are collapsed the same within and across the color elements,
+// so "Hello @X1F world" has a single space. It is off by default, as it breaks the layout of art.
+func WithCompactWhitespace() Option {
+	return func(c *config) {
+		c.compact = true
+	}
+}
+
+// WithBlankLines breaks the [Screens] at every run of n or more consecutive blank lines,
+// in addition to the @CLS@ clear screen control and the form feed, for the files that separate
+// their sections with many blank lines. The blank lines are removed with the screen break.
+// The default, or an n of less than 1, only breaks the screens at the clear screen controls.
+func WithBlankLines(n int) Option {
+	return func(c *config) {
+		c.blankLines = n
+	}
+}
+
+// WithLineEnds writes every newline outside of the color elements, so each line of the source
+// has its own elements that end on the same line of the HTML, for example "<i class="PB0 PF7">Hello</i>\n".
+// The rendered HTML of version controlled art then has a readable diff line by line,
+// while its display is unchanged, as the newlines have no colors.
+func WithLineEnds() Option {
+	return func(c *config) {
+		c.ends = true
+	}
+}
+
+// WithTransparentX00 treats the PCBoard @X00 code as "no change", that keeps the current colors,
+// instead of the default black on black. Some boards used @X00 as a transparent separator in
+// their menus, while others used it to hide text, so the default is the literal black on black.
+// The text of a transparent @X00 is still a separate color element. The option also applies to
+// the Telegard `00 and Wildcat! @00@ equivalents, but not to the documents that mix PCBoard and ANSI.
+func WithTransparentX00() Option {
+	return func(c *config) {
+		c.inherit = true
+	}
+}
+
+// WithTransform applies the fn function to the text content of each run of the HTML, for example to fold
+// the case or to substitute the block characters, while the color codes and the color elements are unchanged.
+// The fn runs before the HTML escaping and the other options, such as [WithLinks], so its result is escaped
+// and should not contain HTML. Like the content, the returned text should be UTF-8 for the HTML.
+// The fn must not retain or modify the content argument. A nil fn is ignored.
+func WithTransform(fn func(content []byte) []byte) Option {
+	return func(c *config) {
+		c.transform = fn
+	}
+}
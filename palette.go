@@ -0,0 +1,86 @@
+package bbs
+
+import "image/color"
+
+// CGAPalette is the standard 16 color CGA and EGA palette that is assumed by the CSS color classes.
+// It is indexed by the 4-bit color values used by PCBoard, Renegade, Telegard, Wildcat! and WWIV,
+// where values 8 to 15 are the high intensity variants of the first 8 colors.
+var CGAPalette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, // black
+	{0x00, 0x00, 0xaa, 0xff}, // blue
+	{0x00, 0xaa, 0x00, 0xff}, // green
+	{0x00, 0xaa, 0xaa, 0xff}, // cyan
+	{0xaa, 0x00, 0x00, 0xff}, // red
+	{0xaa, 0x00, 0xaa, 0xff}, // magenta
+	{0xaa, 0x55, 0x00, 0xff}, // brown
+	{0xaa, 0xaa, 0xaa, 0xff}, // grey
+	{0x55, 0x55, 0x55, 0xff}, // dark grey
+	{0x55, 0x55, 0xff, 0xff}, // light blue
+	{0x55, 0xff, 0x55, 0xff}, // light green
+	{0x55, 0xff, 0xff, 0xff}, // light cyan
+	{0xff, 0x55, 0x55, 0xff}, // light red
+	{0xff, 0x55, 0xff, 0xff}, // light magenta
+	{0xff, 0xff, 0x55, 0xff}, // yellow
+	{0xff, 0xff, 0xff, 0xff}, // white
+}
+
+// ColorNames are the names of the CGAPalette colors.
+// The names are also used by the CSS custom properties, for example --lightblue.
+var ColorNames = [16]string{
+	"black",
+	"blue",
+	"green",
+	"cyan",
+	"red",
+	"magenta",
+	"brown",
+	"grey",
+	"darkgrey",
+	"lightblue",
+	"lightgreen",
+	"lightcyan",
+	"lightred",
+	"lightmagenta",
+	"yellow",
+	"white",
+}
+
+// CelerityColors maps the case sensitive Celerity color code letters to the CGAPalette index.
+// The lowercase letters are the low intensity colors, while the uppercase letters
+// are the high intensity variants. There is no dark grey letter, as the |d code
+// is the default color that resets to grey, or to black after the |S background swap.
+var CelerityColors = map[byte]int{
+	'k': 0,
+	'b': 1,
+	'g': 2,
+	'c': 3,
+	'r': 4,
+	'm': 5,
+	'y': 6,
+	'w': 7,
+	'B': 9,
+	'G': 10,
+	'C': 11,
+	'R': 12,
+	'M': 13,
+	'Y': 14,
+	'W': 15,
+}
+
+// CelerityIndex returns the CGAPalette index of the case sensitive Celerity color code letter.
+// The |S swap and |! control codes, and any other letter, are not colors and return false.
+func CelerityIndex(letter byte) (int, bool) {
+	i, ok := CelerityColors[letter]
+	return i, ok
+}
+
+// CelerityLetter returns the Celerity color code letter of the CGAPalette index,
+// it is the inverse of [CelerityIndex]. The dark grey index 8, which has no letter,
+// and an index outside of 0 to 15 return false.
+func CelerityLetter(index int) (byte, bool) {
+	const letters = "kbgcrmyw BGCRMYW"
+	if index < 0 || index >= len(letters) || letters[index] == ' ' {
+		return 0, false
+	}
+	return letters[index], true
+}
@@ -0,0 +1,68 @@
+package bbs_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestCGAPalette_CSS(t *testing.T) {
+	css, err := os.ReadFile("static/css/text_bbs.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range bbs.CGAPalette {
+		prop := fmt.Sprintf("--%s: rgb(%d, %d, %d);", bbs.ColorNames[i], c.R, c.G, c.B)
+		if !strings.Contains(string(css), prop) {
+			t.Errorf("text_bbs.css is missing the palette property %q", prop)
+		}
+	}
+}
+
+func TestCelerityColors(t *testing.T) {
+	seen := map[int]bool{}
+	for code, i := range bbs.CelerityColors {
+		if i < 0 || i >= len(bbs.CGAPalette) {
+			t.Errorf("CelerityColors[%q] = %d is out of range", code, i)
+		}
+		if seen[i] {
+			t.Errorf("CelerityColors[%q] = %d is a duplicate", code, i)
+		}
+		seen[i] = true
+	}
+	// dark grey has no letter, as |d is the default color
+	if want := len(bbs.CGAPalette) - 1; len(seen) != want || seen[8] {
+		t.Errorf("CelerityColors maps %d colors, want %d without dark grey", len(seen), want)
+	}
+}
+
+func TestCelerityIndex(t *testing.T) {
+	for i := range bbs.CGAPalette {
+		letter, ok := bbs.CelerityLetter(i)
+		if i == 8 {
+			if ok {
+				t.Errorf("CelerityLetter(8) = %q, want no dark grey letter", letter)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("CelerityLetter(%d) is not ok", i)
+		}
+		if got, ok := bbs.CelerityIndex(letter); !ok || got != i {
+			t.Errorf("CelerityIndex(%q) = %d, %v, want %d", letter, got, ok, i)
+		}
+	}
+	for _, letter := range []byte("dS!xK0") {
+		if _, ok := bbs.CelerityIndex(letter); ok {
+			t.Errorf("CelerityIndex(%q) is ok, want false", letter)
+		}
+	}
+	for _, i := range []int{-1, 16} {
+		if _, ok := bbs.CelerityLetter(i); ok {
+			t.Errorf("CelerityLetter(%d) is ok, want false", i)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package bbs
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Preview returns a short plain text snippet of src that is suitable for a link preview
+// or a HTML meta description. The snippet is the SAUCE title when src has a SAUCE record,
+// otherwise it is the first line with visible text. The color codes and controls are removed,
+// the whitespace is collapsed to single spaces, and the snippet is truncated to maxLen runes
+// that end with an ellipsis. The src should be decoded to UTF-8 text.
+// ANSI or an invalid BBS only removes the controls, and a maxLen of 0 or less returns an empty string.
+func Preview(src []byte, b BBS, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	if s, ok := ParseSAUCE(src); ok && s.Title != "" {
+		return truncate(strings.Join(strings.Fields(s.Title), " "), maxLen)
+	}
+	p := NormalizeNewlines(TrimControls(trimBOM(TrimSAUCE(src))...)...)
+	if b != ANSI && b.Valid() {
+		buf := bytes.Buffer{}
+		if err := b.Remove(&buf, p...); err == nil {
+			p = buf.Bytes()
+		}
+	}
+	for _, line := range strings.Split(string(p), "\n") {
+		if s := strings.Join(strings.Fields(line), " "); s != "" {
+			return truncate(s, maxLen)
+		}
+	}
+	return ""
+}
+
+// truncate returns the plain text s shortened to n visible characters that end with an ellipsis,
+// when s is longer than n visible characters, see [Truncate].
+func truncate(s string, n int) string {
+	const ellipsis = "…"
+	p := []byte(s)
+	if len(Truncate(p, -1, n)) == len(p) {
+		return s
+	}
+	return strings.TrimRight(string(Truncate(p, -1, n-1)), " ") + ellipsis
+}
@@ -0,0 +1,150 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// ErrRange is returned when a byte range is outside of the source.
+var ErrRange = errors.New("byte range is out of bounds")
+
+// RenderRange writes to buf the HTML equivalent of the src[start:end] window of BBS color codes,
+// using the color state inherited from the codes in src that precede start.
+// It is intended for paginated viewers that only display a portion of a large document.
+//
+// A color code that is split by start belongs to the inherited color state,
+// while a color code that is split by end is excluded from the window.
+func RenderRange(src []byte, b BBS, start, end int, buf *bytes.Buffer, opts ...Option) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	if start < 0 || end > len(src) || start > end {
+		return fmt.Errorf("%w: %d:%d of %d bytes", ErrRange, start, end, len(src))
+	}
+	if b == ANSI {
+		return ErrANSI
+	}
+	if !b.Valid() {
+		return ErrNone
+	}
+	cfg := newConfig(opts...)
+	codes := src
+	if b == WWIVHeart {
+		// the introducers are replaced byte for byte, so the offsets are unchanged
+		codes = cfg.hearts(src)
+	}
+	for _, loc := range b.Regexp().FindAllIndex(codes, -1) {
+		if loc[0] < start && loc[1] > start {
+			start = loc[1]
+		}
+		if loc[0] < end && loc[1] > end {
+			end = loc[0]
+		}
+	}
+	if start >= end {
+		return nil
+	}
+	state := cfg.stateAt(src, b, start)
+	if len(state) == 0 {
+		return b.HTML(buf, src[start:end], opts...)
+	}
+	// the state codes that precede the last code have no content,
+	// so their empty elements are rendered on their own and then trimmed from the window
+	empty := bytes.Buffer{}
+	if err := b.HTML(&empty, bytes.Join(state[:len(state)-1], nil), append(opts[:len(opts):len(opts)], keepTrailing())...); err != nil {
+		return err
+	}
+	p := append(bytes.Join(state, nil), src[start:end]...)
+	win := bytes.Buffer{}
+	if err := b.HTML(&win, p, opts...); err != nil {
+		return err
+	}
+	_, err := buf.Write(bytes.TrimPrefix(win.Bytes(), empty.Bytes()))
+	return err
+}
+
+// stateAt returns the fewest color codes of the BBS format that replay the color state of src
+// at the offset. The codes are returned in the order of use. The state is resolved from the runs of
+// src that precede the offset using the options, so the WithTransparentX00, WithBareReset and WithHeart
+// options apply to the state as they do to a full render. It is nil for the text without color.
+func (c config) stateAt(src []byte, b BBS, offset int) [][]byte {
+	sc := c.split()
+	// the markers keep the Celerity swaps that have no content
+	sc.Markers = true
+	runs, err := b.splitRuns(c, sc, src[:offset])
+	if err != nil {
+		return nil
+	}
+	var last *split.Run
+	swap := false
+	for i, r := range runs {
+		if r.Marker == split.MarkerSwap {
+			swap = !swap
+		}
+		switch {
+		case r.Marker == split.MarkerReset:
+			last = nil
+		case !r.Plain:
+			last = &runs[i]
+		}
+	}
+	if b == Celerity {
+		return celerityState(last, swap)
+	}
+	if last == nil {
+		return nil
+	}
+	bg, fg := strings.TrimPrefix(last.Background, split.IceBackground), last.Foreground
+	switch b {
+	case PCBoard:
+		return [][]byte{[]byte("@X" + bg + fg)}
+	case Telegard:
+		return [][]byte{[]byte("`" + bg + fg)}
+	case Wildcat:
+		return [][]byte{[]byte("@" + bg + fg + "@")}
+	case Renegade:
+		return vbarsState(bg, fg)
+	case WWIVHash:
+		return [][]byte{[]byte("|#" + fg)}
+	case WWIVHeart:
+		return [][]byte{[]byte("\x03" + fg)}
+	}
+	return nil
+}
+
+// vbarsState returns the vertical bar codes of the background and foreground values of a run,
+// the background is omitted when it is the default of no background code.
+func vbarsState(bg, fg string) [][]byte {
+	n, err := strconv.Atoi(fg)
+	if err != nil {
+		return nil
+	}
+	state := [][]byte{}
+	if bg != "0" {
+		state = append(state, []byte("|"+bg))
+	}
+	return append(state, fmt.Appendf(nil, "|%02d", n))
+}
+
+// celerityState returns the Celerity codes that replay the background and foreground colors
+// of the last run and the background swap.
+func celerityState(last *split.Run, swap bool) [][]byte {
+	const defaultBg = "k"
+	swapCode := []byte("|S")
+	state := [][]byte{}
+	if last != nil {
+		if last.Background != defaultBg {
+			state = append(state, swapCode, []byte("|"+last.Background), swapCode)
+		}
+		state = append(state, []byte("|"+last.Foreground))
+	}
+	if swap {
+		state = append(state, swapCode)
+	}
+	return state
+}
@@ -0,0 +1,85 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestRenderRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		b          bbs.BBS
+		start, end int
+		want       string
+		wantErr    error
+	}{
+		{"nil state", "Hello @X1Fworld", bbs.PCBoard, 0, 5, "Hello", nil},
+		{"pcboard", "@X07Hello @X1Fworld", bbs.PCBoard, 15, 19, "<i class=\"PB1 PFF\">orld</i>", nil},
+		{"pcboard first", "@X07Hello @X1Fworld", bbs.PCBoard, 4, 9, "<i class=\"PB0 PF7\">Hello</i>", nil},
+		{"pcboard codes", "@X07Hello @X1Fworld", bbs.PCBoard, 6, 19,
+			"<i class=\"PB0 PF7\">llo </i><i class=\"PB1 PFF\">world</i>", nil},
+		{"split start", "@X07Hello @X1Fworld", bbs.PCBoard, 12, 19, "<i class=\"PB1 PFF\">world</i>", nil},
+		{"split end", "@X07Hello @X1Fworld", bbs.PCBoard, 4, 12, "<i class=\"PB0 PF7\">Hello </i>", nil},
+		{"wildcat", "@07@Hello @1F@world", bbs.Wildcat, 15, 19, "<i class=\"PB1 PFF\">orld</i>", nil},
+		{"renegade", "|17|03Hi |12there", bbs.Renegade, 12, 17,
+			"<i class=\"P17 P12\">there</i>", nil},
+		{"renegade inherit", "|17|03Hi |12there", bbs.Renegade, 7, 9,
+			"<i class=\"P17 P3\">i </i>", nil},
+		{"celerity", "|S|b|S|wHi |rthere", bbs.Celerity, 13, 18, "<i class=\"PBb PFr\">there</i>", nil},
+		{"celerity swap", "|S|b|S|wHi |S|rthere", bbs.Celerity, 15, 20, "<i class=\"PBr PFw\">there</i>", nil},
+		{"empty", "@X07Hello", bbs.PCBoard, 4, 4, "", nil},
+		{"out of range", "@X07Hello", bbs.PCBoard, 4, 10, "", bbs.ErrRange},
+		{"reversed", "@X07Hello", bbs.PCBoard, 5, 4, "", bbs.ErrRange},
+		{"ansi", "Hello", bbs.ANSI, 0, 5, "", bbs.ErrANSI},
+		{"invalid", "Hello", -1, 0, 5, "", bbs.ErrNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			err := bbs.RenderRange([]byte(tt.src), tt.b, tt.start, tt.end, &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RenderRange() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("RenderRange() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRange_options(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		b          bbs.BBS
+		start, end int
+		opts       []bbs.Option
+		want       string
+	}{
+		{"transparent x00", "@X1FHi @X00there", bbs.PCBoard, 11, 16,
+			[]bbs.Option{bbs.WithTransparentX00()}, "<i class=\"PB1 PFF\">there</i>"},
+		{"x00", "@X1FHi @X00there", bbs.PCBoard, 11, 16, nil, "<i class=\"PB0 PF0\">there</i>"},
+		{"bare reset", "@X1FHi@X there", bbs.PCBoard, 8, 14, []bbs.Option{bbs.WithBareReset()}, " there"},
+		{"bare x", "@X1FHi@X there", bbs.PCBoard, 8, 14, nil, "<i class=\"PB1 PFF\"> there</i>"},
+		{"heart", "\x041Hi \x042there", bbs.WWIVHeart, 7, 12,
+			[]bbs.Option{bbs.WithHeart(0x04)}, "<i class=\"P0 P2\">there</i>"},
+		{"heart split", "\x041Hi \x042there", bbs.WWIVHeart, 6, 12,
+			[]bbs.Option{bbs.WithHeart(0x04)}, "<i class=\"P0 P2\">there</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := bbs.RenderRange([]byte(tt.src), tt.b, tt.start, tt.end, &got, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("RenderRange() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
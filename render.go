@@ -0,0 +1,72 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RenderBytes detects the BBS color format of src and returns its HTML and the [BBS.Name] of the format,
+// such as "PCBoard". It is the same as [HTML] without the io.Reader and *bytes.Buffer values,
+// which is simpler to call across a boundary such as WebAssembly and JavaScript.
+//
+// On error, the html is nil, while the format is the detected format name, if there is one.
+func RenderBytes(src []byte, opts ...Option) (html []byte, format string, err error) {
+	buf := bytes.Buffer{}
+	b, err := HTML(&buf, bytes.NewReader(src), opts...)
+	if err != nil {
+		return nil, b.Name(), err
+	}
+	return buf.Bytes(), b.Name(), nil
+}
+
+// RenderMulti splits the reader at each sep separator into segments, such as the files of a pack,
+// then detects the format of each segment and writes its HTML to w, see [HTML].
+// The separators are not written. It returns the format of each segment in order,
+// where a segment without any color codes is written as escaped text and its format is -1.
+//
+// The color state of each segment is independent, as every element is closed at the end of a segment.
+// An empty sep is a single segment. The [WithMaxSize] option limits the size of the reader,
+// not each segment. An error of a segment, such as an ANSI segment, is returned with the formats
+// of the previous segments, and it wraps the error with the segment index.
+func RenderMulti(r io.Reader, sep []byte, w io.Writer, opts ...Option) ([]BBS, error) {
+	if w == nil {
+		return nil, ErrBuff
+	}
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	r, err := cfg.gunzip(r)
+	if err != nil {
+		return nil, err
+	}
+	scratch := cfg.scratch()
+	defer cfg.release(scratch)
+	p, err := readAll(r, cfg.maxSize, scratch)
+	if err != nil {
+		return nil, err
+	}
+	segments := [][]byte{p}
+	if len(sep) > 0 {
+		segments = bytes.Split(p, sep)
+	}
+	formats := make([]BBS, 0, len(segments))
+	buf := bytes.Buffer{}
+	for i, segment := range segments {
+		buf.Reset()
+		b, err := HTML(&buf, bytes.NewReader(segment), opts...)
+		if errors.Is(err, ErrNone) {
+			b, err = -1, cfg.split().Escape.Write(&buf, segment)
+		}
+		if err != nil {
+			return formats, fmt.Errorf("segment %d: %w", i, err)
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return formats, err
+		}
+		formats = append(formats, b)
+	}
+	return formats, nil
+}
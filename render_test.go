@@ -0,0 +1,97 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestRenderBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		format string
+		err    error
+	}{
+		{"pcboard", "@X0FHello", "PCBoard", nil},
+		{"celerity", "|wHello", "Celerity", nil},
+		{"wwiv", "|#1Hello", "WWIV #", nil},
+		{"none", "Hello", "", bbs.ErrNone},
+		{"ansi", "\x1b[0mHello", "ANSI", bbs.ErrANSI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, format, err := bbs.RenderBytes([]byte(tt.src))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("RenderBytes() error = %v, want %v", err, tt.err)
+			}
+			if format != tt.format {
+				t.Errorf("RenderBytes() format = %q, want %q", format, tt.format)
+			}
+			if err != nil {
+				if html != nil {
+					t.Errorf("RenderBytes() html = %q, want nil", html)
+				}
+				return
+			}
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, strings.NewReader(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(html, buf.Bytes()) {
+				t.Errorf("RenderBytes() = %q, want %q", html, buf.Bytes())
+			}
+		})
+	}
+	html, _, err := bbs.RenderBytes([]byte("@X0FHello"), bbs.WithPrefix("bbs-"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(html, []byte("bbs-F")) {
+		t.Errorf("RenderBytes() = %q, want the bbs- prefix", html)
+	}
+}
+
+func TestRenderMulti(t *testing.T) {
+	const sep = "\x00--\x00"
+	src := strings.Join([]string{"@X1FHello", "|wHello", "Plain <text>", "|07Hi", ""}, sep)
+	w := bytes.Buffer{}
+	got, err := bbs.RenderMulti(strings.NewReader(src), []byte(sep), &w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bbs.BBS{bbs.PCBoard, bbs.Celerity, -1, bbs.Renegade, -1}
+	if !slices.Equal(got, want) {
+		t.Errorf("RenderMulti() = %v, want %v", got, want)
+	}
+	const html = `<i class="PB1 PFF">Hello</i>` + `<i class="PBk PFw">Hello</i>` +
+		`Plain &lt;text&gt;` + `<i class="P0 P7">Hi</i>`
+	if w.String() != html {
+		t.Errorf("RenderMulti() wrote %q, want %q", w.String(), html)
+	}
+
+	w.Reset()
+	got, err = bbs.RenderMulti(strings.NewReader("@X07Hi"+sep+"\x1b[0mHi"), []byte(sep), &w)
+	if !errors.Is(err, bbs.ErrANSI) || !strings.Contains(err.Error(), "segment 1") {
+		t.Errorf("RenderMulti() error = %v, want %v of segment 1", err, bbs.ErrANSI)
+	}
+	if !slices.Equal(got, []bbs.BBS{bbs.PCBoard}) {
+		t.Errorf("RenderMulti() = %v, want the first segment", got)
+	}
+
+	got, err = bbs.RenderMulti(strings.NewReader(src), nil, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("RenderMulti() without a separator = %v, want a single segment", got)
+	}
+	if _, err := bbs.RenderMulti(strings.NewReader(src), []byte(sep), nil); !errors.Is(err, bbs.ErrBuff) {
+		t.Errorf("RenderMulti() error = %v, want %v", err, bbs.ErrBuff)
+	}
+}
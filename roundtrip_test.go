@@ -0,0 +1,87 @@
+package bbs_test
+
+import (
+	"bytes"
+	"html"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+// visibleHTML returns the text of the rendered HTML with the tags removed and the entities unescaped.
+func visibleHTML(t *testing.T, b bbs.BBS, src []byte) string {
+	t.Helper()
+	buf := bytes.Buffer{}
+	if err := b.HTML(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	tags := regexp.MustCompile(`<[^>]*>`)
+	return html.UnescapeString(tags.ReplaceAllString(buf.String(), ""))
+}
+
+// visibleText returns the text of src with the color codes removed.
+func visibleText(t *testing.T, b bbs.BBS, src []byte) string {
+	t.Helper()
+	p := bbs.NormalizeNewlines(bbs.TrimControls(src...)...)
+	buf := bytes.Buffer{}
+	if err := b.Remove(&buf, p...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// generate returns a pseudo random source of the tokens.
+func generate(r *rand.Rand, tokens []string) []byte {
+	const maxTokens = 12
+	s := strings.Builder{}
+	for range r.Intn(maxTokens) + 1 {
+		s.WriteString(tokens[r.Intn(len(tokens))])
+	}
+	return []byte(s.String())
+}
+
+func TestRoundTrip(t *testing.T) {
+	text := []string{"Hello", " ", "world", "\n", "<b>", "&amp;", "a1"}
+	tests := []struct {
+		b     bbs.BBS
+		codes []string
+	}{
+		{bbs.Celerity, []string{"|k", "|w", "|B", "|W", "|S", "|!", "|s", "|Z", "|", "@"}},
+		{bbs.PCBoard, []string{"@X07", "@X1F", "@xab", "@XF0", "@X0G", "@X", "@X0", "@", "|"}},
+		{bbs.Renegade, []string{"|00", "|07", "|15", "|20", "|23", "|24", "|5", "|", "@"}},
+		{bbs.Telegard, []string{"`07", "`1F", "`ab", "`0G", "`|7", "`", "|"}},
+		{bbs.Wildcat, []string{"@07@", "@1F@", "@0b@", "@GG@", "@0@", "@", "|"}},
+		{bbs.WWIVHash, []string{"|#0", "|#7", "|#9", "|#", "|#x", "|", "@"}},
+		{bbs.WWIVHeart, []string{"\x030", "\x037", "♥9", "♥", "\x03"}},
+	}
+	const runs = 500
+	for _, tt := range tests {
+		t.Run(tt.b.Name(), func(t *testing.T) {
+			r := rand.New(rand.NewSource(int64(tt.b)))
+			tokens := append(append([]string{}, text...), tt.codes...)
+			for range runs {
+				src := generate(r, tokens)
+				want := visibleText(t, tt.b, src)
+				if got := visibleHTML(t, tt.b, src); got != want {
+					t.Fatalf("%q: HTML text = %q, Remove text = %q", src, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip_examples(t *testing.T) {
+	for _, name := range []string{"static/examples/hello.pcb", "static/examples/pcboard.txt"} {
+		src, err := static.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := visibleText(t, bbs.PCBoard, src)
+		if got := visibleHTML(t, bbs.PCBoard, src); got != want {
+			t.Errorf("%s: HTML text = %q, Remove text = %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package bbs
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// A Run is a substring of text with its fully resolved colors, so the colors include
+// the state inherited from the earlier color codes, such as a Celerity background.
+type Run struct {
+	Foreground int    // Foreground is the CGAPalette index of the text color.
+	Background int    // Background is the CGAPalette index of the background color.
+	Text       string // Text is the content without the color codes.
+}
+
+// Default colors of the text that has no color code, grey on black.
+const (
+	defaultForeground = 7
+	defaultBackground = 0
+)
+
+// Runs splits the io.Reader into runs of text with their resolved colors.
+// Unlike [Fields], which returns the substrings with their leading color code,
+// each run is self-describing, which is useful when rebuilding the art without HTML.
+// An error is returned if no color codes are found or if ANSI control sequences are first found.
+func Runs(src io.Reader, opts ...Option) ([]Run, BBS, error) {
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, -1, err
+	}
+	src, err := cfg.gunzip(src)
+	if err != nil {
+		return nil, -1, err
+	}
+	scratch := cfg.scratch()
+	defer cfg.release(scratch)
+	f, b, err := cfg.findAll(src, scratch)
+	if err != nil {
+		return nil, -1, err
+	}
+	if f == ANSI {
+		return nil, -1, ErrANSI
+	}
+	if !f.Valid() {
+		return nil, -1, ErrNone
+	}
+	runs, err := f.Runs(b, opts...)
+	if err != nil {
+		return nil, -1, err
+	}
+	return runs, f, nil
+}
+
+// Runs returns the runs of text in src with their resolved colors, using the same
+// color state as the HTML renderers. The text before the first color code, or any
+// text without a color code, uses the default colors of grey on black.
+// Like the HTML renderers, a leading UTF-8 byte order mark is removed,
+// the CRLF and CR line endings are normalized to LF newlines,
+// and the color codes at the end of src that have no content are dropped.
+func (b BBS) Runs(src []byte, opts ...Option) ([]Run, error) {
+	cfg := newConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.strict(b, src); err != nil {
+		return nil, err
+	}
+	c := cfg.split()
+	runs, err := b.splitRuns(cfg, c, src)
+	if err != nil {
+		return nil, err
+	}
+	runs = c.Trailing(runs)
+	res := make([]Run, 0, len(runs))
+	for _, r := range runs {
+		run := Run{Foreground: defaultForeground, Background: defaultBackground, Text: r.Content}
+		if !r.Plain {
+			run.Foreground, run.Background = b.index(r.Foreground), b.index(r.Background)
+		}
+		res = append(res, run)
+	}
+	return res, nil
+}
+
+// splitRuns returns the runs of src using the split configuration c of the options cfg,
+// after the same preparation of src as the HTML renderers.
+func (b BBS) splitRuns(cfg config, c split.Config, src []byte) ([]split.Run, error) {
+	if b != PCBoard {
+		c.Reset = false
+	}
+	if b == WWIVHeart {
+		src = cfg.hearts(src)
+	}
+	p, err := cfg.decode(trimBOM(src))
+	if err != nil {
+		return nil, err
+	}
+	p = NormalizeNewlines(TrimControls(p...)...)
+	switch b {
+	case ANSI:
+		return nil, ErrANSI
+	case Celerity:
+		return c.CelerityRuns(p), nil
+	case PCBoard:
+		return c.PCBoardRuns(p), nil
+	case Renegade:
+		return c.VBarsRuns(p), nil
+	case Telegard:
+		return c.PCBoardRuns(telegard(p)), nil
+	case Wildcat:
+		return c.PCBoardRuns(wildcat(p)), nil
+	case WWIVHash:
+		return c.VBarsRuns(wwivHash(p)), nil
+	case WWIVHeart:
+		return c.VBarsRuns(wwivHeart(p)), nil
+	}
+	return nil, ErrNone
+}
+
+// index returns the CGAPalette index of the color value in the notation of the format.
+func (b BBS) index(color string) int {
+	const firstBackground = 16
+	switch b {
+	case Celerity:
+		if len(color) == 1 {
+			return CelerityColors[color[0]]
+		}
+	case PCBoard, Telegard, Wildcat:
+		n, _ := strconv.ParseUint(strings.TrimPrefix(color, split.IceBackground), 16, 8)
+		return int(n)
+	case Renegade, WWIVHash, WWIVHeart:
+		n, _ := strconv.Atoi(color)
+		return n % firstBackground
+	}
+	return 0
+}
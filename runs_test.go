@@ -0,0 +1,68 @@
+package bbs_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestBBS_Runs(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want []bbs.Run
+	}{
+		{"text", bbs.PCBoard, "Hello", []bbs.Run{{7, 0, "Hello"}}},
+		{"pcboard", bbs.PCBoard, "Hi @X1Fthere\r\n@X04!", []bbs.Run{{7, 0, "Hi "}, {15, 1, "there\n"}, {4, 0, "!"}}},
+		{"celerity", bbs.Celerity, "|rHi |S|bthere |S|Y!", []bbs.Run{{4, 0, "Hi "}, {4, 1, "there "}, {14, 1, "!"}}},
+		{"renegade", bbs.Renegade, "|17Hi |12there |20!", []bbs.Run{{0, 1, "Hi "}, {12, 1, "there "}, {12, 4, "!"}}},
+		{"telegard", bbs.Telegard, "`1FHi", []bbs.Run{{15, 1, "Hi"}}},
+		{"wildcat", bbs.Wildcat, "@1F@Hi", []bbs.Run{{15, 1, "Hi"}}},
+		{"wwiv hash", bbs.WWIVHash, "|#3Hi", []bbs.Run{{3, 0, "Hi"}}},
+		{"wwiv heart", bbs.WWIVHeart, "\x033Hi", []bbs.Run{{3, 0, "Hi"}}},
+		{"trailing codes", bbs.PCBoard, "@X1FHi @X07@X4E", []bbs.Run{{15, 1, "Hi "}}},
+		{"trailing renegade", bbs.Renegade, "|17Hi|07|16", []bbs.Run{{0, 1, "Hi"}}},
+		{"codes only", bbs.PCBoard, "@X1F@X07", []bbs.Run{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.b.Runs([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BBS.Runs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := bbs.ANSI.Runs(nil); !errors.Is(err, bbs.ErrANSI) {
+		t.Errorf("BBS.Runs() error = %v, want %v", err, bbs.ErrANSI)
+	}
+	if _, err := bbs.BBS(-1).Runs(nil); !errors.Is(err, bbs.ErrNone) {
+		t.Errorf("BBS.Runs() error = %v, want %v", err, bbs.ErrNone)
+	}
+}
+
+func TestRuns(t *testing.T) {
+	got, b, err := bbs.Runs(strings.NewReader("|S|b|S|wHello |Yworld"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != bbs.Celerity {
+		t.Errorf("Runs() = %v, want %v", b, bbs.Celerity)
+	}
+	want := []bbs.Run{{7, 1, ""}, {7, 1, "Hello "}, {14, 1, "world"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Runs() = %v, want %v", got, want)
+	}
+	if _, _, err := bbs.Runs(strings.NewReader("Hello world")); !errors.Is(err, bbs.ErrNone) {
+		t.Errorf("Runs() error = %v, want %v", err, bbs.ErrNone)
+	}
+	if _, _, err := bbs.Runs(strings.NewReader("\x1b[0mHello world")); !errors.Is(err, bbs.ErrANSI) {
+		t.Errorf("Runs() error = %v, want %v", err, bbs.ErrANSI)
+	}
+}
@@ -0,0 +1,168 @@
+package bbs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// SAUCE is the Standard Architecture for Universal Comment Extensions metadata record
+// that is appended to the end of many BBS art files, see https://www.acid.org/info/sauce/sauce.htm.
+type SAUCE struct {
+	Title    string    // Title of the art.
+	Author   string    // Author is the name or handle of the artist.
+	Group    string    // Group is the name of the art group or company.
+	Date     string    // Date of creation using the CCYYMMDD format.
+	FileSize uint32    // FileSize is the original size of the file without the SAUCE record.
+	DataType uint8     // DataType is the type of data, 1 is character based text.
+	FileType uint8     // FileType is the type of file, for character data 0 is ASCII, 1 is ANSI.
+	TInfo    [4]uint16 // TInfo are the numeric information fields, such as the character width.
+	Flags    uint8     // Flags are the ANSiFlags of the text, such as the iCE colors bit.
+	Font     string    // Font is the name of the font, the TInfoS field.
+	Comments []string  // Comments are the lines of the optional comment block.
+}
+
+// Sizes and identifiers of the SAUCE record.
+const (
+	sauceID      = "SAUCE"
+	sauceSize    = 128
+	comntID      = "COMNT"
+	comntLine    = 64
+	sauceEOF     = 0x1a // the end of file marker that precedes the metadata
+	sauceComment = 104  // the offset of the number of comment lines
+)
+
+// ParseSAUCE returns the SAUCE metadata record at the end of src.
+// The text fields are decoded from CP-437 to UTF-8 and have their padding removed.
+// If src does not end with a SAUCE record false is returned.
+func ParseSAUCE(src []byte) (SAUCE, bool) {
+	if len(src) < sauceSize {
+		return SAUCE{}, false
+	}
+	r := src[len(src)-sauceSize:]
+	if !bytes.HasPrefix(r, []byte(sauceID)) {
+		return SAUCE{}, false
+	}
+	le := binary.LittleEndian
+	s := SAUCE{
+		Title:    sauceText(r[7:42]),
+		Author:   sauceText(r[42:62]),
+		Group:    sauceText(r[62:82]),
+		Date:     sauceText(r[82:90]),
+		FileSize: le.Uint32(r[90:94]),
+		DataType: r[94],
+		FileType: r[95],
+		Flags:    r[105],
+		Font:     sauceText(r[106:128]),
+	}
+	for i := range s.TInfo {
+		s.TInfo[i] = le.Uint16(r[96+i*2:])
+	}
+	if start, ok := comments(src); ok {
+		block := src[start+len(comntID) : len(src)-sauceSize]
+		for i := 0; i+comntLine <= len(block); i += comntLine {
+			s.Comments = append(s.Comments, sauceText(block[i:i+comntLine]))
+		}
+	}
+	return s, true
+}
+
+// TrimSAUCE returns src without the SAUCE metadata record, the comment block,
+// and the end of file marker that precedes them. If src does not end with
+// a SAUCE record it is returned unchanged.
+func TrimSAUCE(src []byte) []byte {
+	if _, ok := ParseSAUCE(src); !ok {
+		return src
+	}
+	end := len(src) - sauceSize
+	if start, ok := comments(src); ok {
+		end = start
+	}
+	return bytes.TrimSuffix(src[:end], []byte{sauceEOF})
+}
+
+// Regions splits src into the renderable body, the SAUCE region and the binary tail between them,
+// so the body can be rendered and the SAUCE metadata stored separately, see [ParseSAUCE].
+// The SAUCE region is the end of file marker, the comment block and the record removed by [TrimSAUCE],
+// while the binary tail is the arbitrary binary data removed by [TrimBinaryTail].
+// The regions are sub-slices of src, joining the body, the tail and the sauce returns src,
+// and a region that is absent is an empty slice.
+func Regions(src []byte) (body, sauce, tail []byte) {
+	rest := TrimSAUCE(src)
+	body = TrimBinaryTail(rest)
+	return body, src[len(rest):], rest[len(body):]
+}
+
+// SAUCE data and file types of the character based text.
+const (
+	character  = 1 // character is the SAUCE data type of text.
+	ansiFile   = 1 // ansiFile is the ANSi file type.
+	ansiMation = 2 // ansiMation is the animated ANSi file type.
+	pcbFile    = 4 // pcbFile is the PCBoard file type.
+)
+
+// BBS returns the BBS color format declared by the SAUCE data and file types,
+// which are the ANSi, ANSiMation and PCBoard file types of character based text.
+// Other types, such as ASCII or None, do not declare a format and return -1.
+func (s SAUCE) BBS() BBS {
+	if s.DataType != character {
+		return -1
+	}
+	switch s.FileType {
+	case ansiFile, ansiMation:
+		return ANSI
+	case pcbFile:
+		return PCBoard
+	}
+	return -1
+}
+
+// sauceFormat returns the BBS color format declared by the SAUCE record at the end of the reader,
+// and returns the reader to its current offset. If there is no declared format -1 is returned.
+func sauceFormat(rs io.ReadSeeker) BBS {
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	defer func() {
+		_, _ = rs.Seek(start, io.SeekStart)
+	}()
+	if _, err := rs.Seek(-sauceSize, io.SeekEnd); err != nil {
+		return -1
+	}
+	p := make([]byte, sauceSize)
+	if _, err := io.ReadFull(rs, p); err != nil {
+		return -1
+	}
+	s, ok := ParseSAUCE(p)
+	if !ok {
+		return -1
+	}
+	return s.BBS()
+}
+
+// comments returns the offset of the SAUCE comment block, if it exists.
+func comments(src []byte) (int, bool) {
+	n := int(src[len(src)-sauceSize+sauceComment])
+	if n == 0 {
+		return 0, false
+	}
+	start := len(src) - sauceSize - n*comntLine - len(comntID)
+	if start < 0 || !bytes.HasPrefix(src[start:], []byte(comntID)) {
+		return 0, false
+	}
+	return start, true
+}
+
+// sauceText returns the CP-437 field as UTF-8 text without the space and null padding.
+func sauceText(p []byte) string {
+	p = bytes.TrimRight(p, " \x00")
+	s, err := charmap.CodePage437.NewDecoder().Bytes(p)
+	if err != nil {
+		return strings.TrimSpace(string(p))
+	}
+	return strings.TrimSpace(string(s))
+}
@@ -0,0 +1,188 @@
+package bbs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+// sauce returns a SAUCE record with the comment lines.
+func sauce(title string, flags byte, comments ...string) []byte {
+	pad := func(s string, n int) []byte {
+		return append([]byte(s), bytes.Repeat([]byte(" "), n-len(s))...)
+	}
+	buf := bytes.Buffer{}
+	buf.WriteByte(0x1a)
+	if len(comments) > 0 {
+		buf.WriteString("COMNT")
+		for _, c := range comments {
+			buf.Write(pad(c, 64))
+		}
+	}
+	buf.WriteString("SAUCE00")
+	buf.Write(pad(title, 35))
+	buf.Write(pad("artist", 20))
+	buf.Write(pad("group", 20))
+	buf.WriteString("19960101")
+	buf.Write(binary.LittleEndian.AppendUint32(nil, 42))
+	buf.Write([]byte{1, 0})
+	buf.Write(binary.LittleEndian.AppendUint16(nil, 80))
+	buf.Write(make([]byte, 6))
+	buf.Write([]byte{byte(len(comments)), flags})
+	buf.Write(make([]byte, 22))
+	return buf.Bytes()
+}
+
+func TestParseSAUCE(t *testing.T) {
+	const art = "@X07Hello world\r\n"
+	src := append([]byte(art), sauce("Hello", 1, "first comment", "second")...)
+	s, ok := bbs.ParseSAUCE(src)
+	if !ok {
+		t.Fatal("ParseSAUCE() is not ok")
+	}
+	want := bbs.SAUCE{
+		Title: "Hello", Author: "artist", Group: "group", Date: "19960101",
+		FileSize: 42, DataType: 1, FileType: 0, TInfo: [4]uint16{80}, Flags: 1,
+		Comments: []string{"first comment", "second"},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("ParseSAUCE() = %+v, want %+v", s, want)
+	}
+	if got := bbs.TrimSAUCE(src); string(got) != art {
+		t.Errorf("TrimSAUCE() = %q, want %q", got, art)
+	}
+	src = append([]byte(art), sauce("Hello", 0)...)
+	if got := bbs.TrimSAUCE(src); string(got) != art {
+		t.Errorf("TrimSAUCE() = %q, want %q", got, art)
+	}
+	if _, ok := bbs.ParseSAUCE([]byte(art)); ok {
+		t.Error("ParseSAUCE() without a record is ok")
+	}
+	if got := bbs.TrimSAUCE([]byte(art)); string(got) != art {
+		t.Errorf("TrimSAUCE() = %q, want %q", got, art)
+	}
+	// the fixtures have SAUCE records, except for the mixed PCBoard and ANSI file
+	names, err := filepath.Glob(filepath.Join("testdata", "*.[pt][cx][bt]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if filepath.Base(name) == "pcboard_ansi.pcb" {
+			continue
+		}
+		p, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s, ok := bbs.ParseSAUCE(p); !ok || s.Title == "" {
+			t.Errorf("ParseSAUCE() of %s = %+v, %v", name, s, ok)
+		}
+		if bytes.Contains(bbs.TrimSAUCE(p), []byte("SAUCE00")) {
+			t.Errorf("TrimSAUCE() of %s contains the record", name)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		b      bbs.BBS
+		maxLen int
+		want   string
+	}{
+		{"empty", "", bbs.PCBoard, 10, ""},
+		{"zero", "Hello", bbs.PCBoard, 0, ""},
+		{"first line", "@CLS@\r\n  \r\n@X07Hello   @X0Fworld\r\nmore", bbs.PCBoard, 40, "Hello world"},
+		{"truncate", "|07Hello wonderful world", bbs.Renegade, 10, "Hello won…"},
+		{"space", "|07Hello wonderful world", bbs.Renegade, 7, "Hello…"},
+		{"runes", "|wÇa va très bien", bbs.Celerity, 8, "Ça va t…"},
+		{"exact", "|wHello", bbs.Celerity, 5, "Hello"},
+		{"ansi", "\x1b[0mHello", bbs.ANSI, 20, "\x1b[0mHello"},
+		{"sauce", "@X07Hello world" + string(sauce("  The   title ", 0)), bbs.PCBoard, 40, "The title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.Preview([]byte(tt.src), tt.b, tt.maxLen); got != tt.want {
+				t.Errorf("Preview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind_sauce(t *testing.T) {
+	const art = "|07Hello @X07world\r\n"
+	record := func(fileType byte) string {
+		r := sauce("Hello", 0)
+		r[len(r)-128+95] = fileType
+		return art + string(r)
+	}
+	tests := []struct {
+		name string
+		src  string
+		want bbs.BBS
+	}{
+		{"none", art, bbs.Renegade},
+		{"ascii", record(0), bbs.Renegade},
+		{"ansi", record(1), bbs.Renegade},
+		{"ansi codes", strings.Replace(record(1), "@X07", "\x1b[0m", 1), bbs.ANSI},
+		{"pcboard", record(4), bbs.PCBoard},
+		{"html", record(6), bbs.Renegade},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := strings.NewReader(tt.src)
+			if got := bbs.Find(r); got != tt.want {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+			buf := bytes.Buffer{}
+			// a reader that is not an io.Seeker uses the record after it is read
+			got, err := bbs.HTML(&buf, reader{strings.NewReader(tt.src)})
+			if err != nil && !errors.Is(err, bbs.ErrANSI) {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// the declared format is only preferred over the formats found in the same line
+	if got := bbs.Find(strings.NewReader("|07Hi\n@X07there" + record(4)[len(art):])); got != bbs.Renegade {
+		t.Errorf("Find() = %v, want %v", got, bbs.Renegade)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	record := sauce("title", 0, "a comment")
+	binaryTail := string(make([]byte, 100))
+	tests := []struct {
+		name                  string
+		src                   string
+		body, sauceRec, trail string
+	}{
+		{"empty", "", "", "", ""},
+		{"text", "@X07Hello", "@X07Hello", "", ""},
+		{"sauce", "@X07Hello" + string(record), "@X07Hello", string(record), ""},
+		{"tail", "@X07Hello" + binaryTail, "@X07Hello", "", binaryTail},
+		{"both", "@X07Hello" + binaryTail + string(record), "@X07Hello", string(record), binaryTail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := []byte(tt.src)
+			body, s, tail := bbs.Regions(src)
+			if string(body) != tt.body || string(s) != tt.sauceRec || string(tail) != tt.trail {
+				t.Errorf("Regions() = %q, %q, %q, want %q, %q, %q", body, s, tail, tt.body, tt.sauceRec, tt.trail)
+			}
+			if joined := string(body) + string(tail) + string(s); joined != tt.src {
+				t.Errorf("Regions() joined = %q, want %q", joined, tt.src)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package bbs
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// A Token is either literal text or a color code of a [Scanner].
+type Token struct {
+	Code       bool   // Code reports whether the token is a color code, otherwise it is literal text.
+	Text       string // Text is the literal text, or the color code as found in the source.
+	Foreground int    // Foreground is the CGAPalette index of the foreground color after the token.
+	Background int    // Background is the CGAPalette index of the background color after the token.
+}
+
+// scanChunk is the number of bytes read from the reader by each read of a Scanner.
+const scanChunk = 4096
+
+// scanHold is the number of bytes at the end of the unread data that are held back
+// until more is read, as they could begin a color code that is split by the reads.
+const scanHold = 8
+
+// Scanner is a streaming tokenizer of the color codes of a BBS format, for the consumers that build
+// their own output, such as a terminal, a canvas or a text user interface, without the HTML.
+// Like a bufio.Scanner, the successive calls to the Scan method step through the tokens of the reader,
+// and each [Token] is either literal text or a color code with the resolved colors that follow the code.
+//
+// The color codes that are split by the reads of the reader are joined, while a partial code
+// at the end of the reader is literal text. A long run of text can be returned as consecutive text tokens,
+// which never split a multibyte UTF-8 sequence. The source is not modified, so the line endings,
+// the byte order mark and the control characters are all kept within the text.
+// The colors use the same state as [BBS.Runs], the text before the first code is grey on black,
+// the Renegade and WWIV codes begin from black on black, so a first code that only sets
+// the background leaves a black foreground, and the PCBoard, Telegard and Wildcat!
+// backgrounds 8 to 15 are kept as their blink values.
+type Scanner struct {
+	r     io.Reader
+	b     BBS
+	buf   []byte
+	token Token
+	fg    int
+	bg    int
+	swap  bool
+	coded bool
+	eof   bool
+	err   error
+}
+
+// NewScanner returns a new Scanner to read the color codes of the BBS format from r.
+// ANSI, an invalid or a registered format is an error returned by the [Scanner.Err] method.
+func NewScanner(r io.Reader, b BBS) *Scanner {
+	s := &Scanner{r: r, b: b, fg: defaultForeground, bg: defaultBackground}
+	switch {
+	case b == ANSI:
+		s.err = ErrANSI
+	case !b.Valid(), r == nil:
+		s.err = ErrNone
+	}
+	return s
+}
+
+// Scan advances the Scanner to the next token, which is then available through the Token method.
+// It returns false when the scan stops, either by reaching the end of the reader or an error.
+// After Scan returns false, the Err method returns any error that occurred during scanning,
+// except that if it was io.EOF, Err will return nil.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for {
+		if len(s.buf) > 0 {
+			if t, ok := s.next(); ok {
+				s.token = t
+				return true
+			}
+		}
+		if s.eof {
+			return false
+		}
+		if err := s.read(); err != nil {
+			s.err = err
+			return false
+		}
+	}
+}
+
+// Token returns the most recent token generated by a call to Scan.
+func (s *Scanner) Token() Token {
+	return s.token
+}
+
+// Err returns the first non-EOF error that was encountered by the Scanner.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// read appends the next chunk of the reader to the unread data.
+func (s *Scanner) read() error {
+	p := make([]byte, scanChunk)
+	n, err := s.r.Read(p)
+	s.buf = append(s.buf, p[:n]...)
+	if errors.Is(err, io.EOF) {
+		s.eof = true
+		return nil
+	}
+	return err
+}
+
+// next returns the next token of the unread data, or false when more data is needed.
+func (s *Scanner) next() (Token, bool) {
+	re := s.b.Regexp()
+	m := re.FindSubmatchIndex(s.buf)
+	if m != nil && m[0] == 0 && (m[1] < len(s.buf) || s.eof) {
+		code := string(s.buf[:m[1]])
+		s.decode(s.buf, m)
+		s.buf = s.buf[m[1]:]
+		return Token{Code: true, Text: code, Foreground: s.fg, Background: s.bg}, true
+	}
+	end := len(s.buf)
+	if m != nil {
+		end = m[0]
+	}
+	if !s.eof {
+		// the held back bytes could begin a code that is completed by the next read
+		end = min(end, len(s.buf)-scanHold)
+		for end > 0 && !utf8.RuneStart(s.buf[end]) {
+			end--
+		}
+	}
+	if end <= 0 {
+		return Token{}, false
+	}
+	text := string(s.buf[:end])
+	s.buf = s.buf[end:]
+	return Token{Text: text, Foreground: s.fg, Background: s.bg}, true
+}
+
+// decode sets the color state of the code of the submatch indexes m within p.
+func (s *Scanner) decode(p []byte, m []int) {
+	hex := func(i int) int {
+		n, _ := strconv.ParseUint(string(p[m[i]:m[i+1]]), 16, 8)
+		return int(n)
+	}
+	if !s.coded {
+		s.coded = true
+		switch s.b {
+		case Renegade, WWIVHash, WWIVHeart:
+			s.fg, s.bg = 0, 0
+		}
+	}
+	switch s.b {
+	case PCBoard:
+		n, _ := strconv.ParseUint(string(p[m[2]:m[3]]), 16, 8)
+		s.bg, s.fg = int(n>>4), int(n&0xf)
+	case Telegard, Wildcat:
+		s.bg, s.fg = hex(2), hex(4)
+	case Renegade, WWIVHash, WWIVHeart:
+		const firstBackground = 16
+		n, _ := strconv.Atoi(string(p[m[2]:m[3]]))
+		if n >= firstBackground {
+			s.bg = n - firstBackground
+			return
+		}
+		s.fg = n
+	case Celerity:
+		const swapCmd, controlCmd, defaultCmd = 'S', '!', 'd'
+		color := defaultForeground
+		switch code := p[m[2]]; code {
+		case swapCmd:
+			s.swap = !s.swap
+			return
+		case controlCmd:
+			return
+		case defaultCmd:
+			if s.swap {
+				color = defaultBackground
+			}
+		default:
+			color = CelerityColors[code]
+		}
+		if s.swap {
+			s.bg = color
+			return
+		}
+		s.fg = color
+	}
+}
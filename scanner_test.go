@@ -0,0 +1,131 @@
+package bbs_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/bengarrett/bbs"
+)
+
+// scanTokens returns the tokens of the scanner, with the consecutive text tokens joined.
+func scanTokens(t *testing.T, s *bbs.Scanner) []bbs.Token {
+	t.Helper()
+	tokens := []bbs.Token{}
+	for s.Scan() {
+		tok := s.Token()
+		if !tok.Code && !utf8.ValidString(tok.Text) {
+			t.Errorf("Token() = %q, splits a UTF-8 sequence", tok.Text)
+		}
+		if last := len(tokens) - 1; last >= 0 && !tok.Code && !tokens[last].Code {
+			tokens[last].Text += tok.Text
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want []bbs.Token
+	}{
+		{"empty", "", bbs.PCBoard, []bbs.Token{}},
+		{"text", "Hello world", bbs.PCBoard, []bbs.Token{{false, "Hello world", 7, 0}}},
+		{"pcboard", "Hi @X1FHello @x9eworld", bbs.PCBoard, []bbs.Token{
+			{false, "Hi ", 7, 0}, {true, "@X1F", 15, 1}, {false, "Hello ", 15, 1},
+			{true, "@x9e", 14, 9}, {false, "world", 14, 9},
+		}},
+		{"partial at eof", "Hello @X1", bbs.PCBoard, []bbs.Token{{false, "Hello @X1", 7, 0}}},
+		{"adjacent", "@X1F@X07", bbs.PCBoard, []bbs.Token{{true, "@X1F", 15, 1}, {true, "@X07", 7, 0}}},
+		{"multibyte", "@X1F╔═══╗\n║ ♥ ║", bbs.PCBoard, []bbs.Token{
+			{true, "@X1F", 15, 1}, {false, "╔═══╗\n║ ♥ ║", 15, 1},
+		}},
+		{"celerity", "|S|b|S|WHi|d", bbs.Celerity, []bbs.Token{
+			{true, "|S", 7, 0}, {true, "|b", 7, 1}, {true, "|S", 7, 1}, {true, "|W", 15, 1},
+			{false, "Hi", 15, 1}, {true, "|d", 7, 1},
+		}},
+		{"renegade", "|17|14Hi|3", bbs.Renegade, []bbs.Token{
+			{true, "|17", 0, 1}, {true, "|14", 14, 1}, {false, "Hi|3", 14, 1},
+		}},
+		{"telegard", "`1EHi", bbs.Telegard, []bbs.Token{{true, "`1E", 14, 1}, {false, "Hi", 14, 1}}},
+		{"wildcat", "@1E@Hi", bbs.Wildcat, []bbs.Token{{true, "@1E@", 14, 1}, {false, "Hi", 14, 1}}},
+		{"wwiv hash", "|#5Hi", bbs.WWIVHash, []bbs.Token{{true, "|#5", 5, 0}, {false, "Hi", 5, 0}}},
+		{"wwiv heart", "♥5Hi", bbs.WWIVHeart, []bbs.Token{{true, "♥5", 5, 0}, {false, "Hi", 5, 0}}},
+	}
+	for _, tt := range tests {
+		for _, one := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s one byte %v", tt.name, one), func(t *testing.T) {
+				r := iotest.DataErrReader(strings.NewReader(tt.src))
+				if one {
+					r = iotest.OneByteReader(r)
+				}
+				got := scanTokens(t, bbs.NewScanner(r, tt.b))
+				if len(got) != len(tt.want) {
+					t.Fatalf("Scanner tokens = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Errorf("Scanner token %d = %v, want %v", i, got[i], tt.want[i])
+					}
+				}
+			})
+		}
+	}
+	long := strings.Repeat("Hello world ", 1000) + "@X1F!"
+	got := scanTokens(t, bbs.NewScanner(strings.NewReader(long), bbs.PCBoard))
+	if len(got) != 3 || got[1].Text != "@X1F" || got[0].Text+got[1].Text+got[2].Text != long {
+		t.Errorf("Scanner of a long text = %d tokens", len(got))
+	}
+	s := bbs.NewScanner(strings.NewReader("\x1b[0m"), bbs.ANSI)
+	if s.Scan() || !errors.Is(s.Err(), bbs.ErrANSI) {
+		t.Errorf("Scanner of ANSI error = %v, want %v", s.Err(), bbs.ErrANSI)
+	}
+	s = bbs.NewScanner(iotest.ErrReader(iotest.ErrTimeout), bbs.PCBoard)
+	if s.Scan() || !errors.Is(s.Err(), iotest.ErrTimeout) {
+		t.Errorf("Scanner of a reader error = %v, want %v", s.Err(), iotest.ErrTimeout)
+	}
+}
+
+func TestScanner_runs(t *testing.T) {
+	tests := map[bbs.BBS]string{
+		bbs.Celerity:  "Hi |S|b|S|WHello |d|Sworld|S|k|!",
+		bbs.PCBoard:   "Hi @X1FHello @x9eworld@X07",
+		bbs.Renegade:  "Hi |17hi|14Hello |20|03world|07",
+		bbs.Telegard:  "Hi `1EHello `9Fworld`07",
+		bbs.Wildcat:   "Hi @1E@Hello @9F@world@07@",
+		bbs.WWIVHash:  "Hi |#5Hello |#1world|#7",
+		bbs.WWIVHeart: "Hi \x035Hello \x031world\x037",
+	}
+	// text returns the runs without the runs of no text
+	text := func(runs []bbs.Run) []bbs.Run {
+		return slices.DeleteFunc(runs, func(r bbs.Run) bool { return r.Text == "" })
+	}
+	for b, src := range tests {
+		t.Run(b.Name(), func(t *testing.T) {
+			want, err := b.Runs([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []bbs.Run{}
+			for _, tok := range scanTokens(t, bbs.NewScanner(strings.NewReader(src), b)) {
+				if !tok.Code {
+					got = append(got, bbs.Run{Foreground: tok.Foreground, Background: tok.Background, Text: tok.Text})
+				}
+			}
+			if got, want := text(got), text(want); !slices.Equal(got, want) {
+				t.Errorf("Scanner runs = %v, want the Runs %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package bbs
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// screenRe matches the screen breaks, the PCBoard @CLS@ clear screen control and the form feed.
+var screenRe = regexp.MustCompile(`@CLS ?@|\f`)
+
+// Screens splits src at the screen breaks into the screens of a paginated reader.
+// The screen breaks are the PCBoard @CLS@ clear screen control and the form feed (0x0C),
+// which are removed, and any empty screens are skipped. The [WithBlankLines] option also breaks
+// the screens at the runs of blank lines, for the files that never used a clear screen control.
+//
+// Each screen keeps its color codes and begins with the color codes of the BBS format
+// that replay the color state inherited from the earlier screens, so every screen
+// can be rendered on its own. ANSI or an invalid BBS only splits the screens.
+func Screens(src []byte, b BBS, opts ...Option) [][]byte {
+	cfg := newConfig(opts...)
+	screens := [][]byte{}
+	start := 0
+	locs := screenRe.FindAllIndex(src, -1)
+	if n := cfg.blankLines; n > 0 {
+		locs = append(locs, blankLines(src, n)...)
+		slices.SortFunc(locs, func(a, b []int) int { return a[0] - b[0] })
+	}
+	locs = append(locs, []int{len(src), len(src)})
+	for _, loc := range locs {
+		if loc[0] == start {
+			start = loc[1]
+			continue
+		}
+		screen := []byte{}
+		if b != ANSI && b.Valid() {
+			screen = bytes.Join(cfg.stateAt(src, b, start), nil)
+		}
+		screens = append(screens, append(screen, src[start:loc[0]]...))
+		start = loc[1]
+	}
+	return screens
+}
+
+// RenderScreens returns the HTML of each screen of src, see [Screens], for a slideshow of a multi-screen
+// sequence, such as a welcome. Each screen is rendered on its own with the color state inherited from
+// the earlier screens, so its HTML is self-contained, while the empty elements at the start of a screen
+// are removed as they have no content to color. The options are used by both Screens and [BBS.HTML].
+// ANSI returns an [ErrANSI] error and an invalid BBS returns an [ErrNone] error.
+func RenderScreens(src []byte, b BBS, opts ...Option) ([]string, error) {
+	if b == ANSI {
+		return nil, ErrANSI
+	}
+	if !b.Valid() {
+		return nil, ErrNone
+	}
+	screens := Screens(src, b, opts...)
+	res := make([]string, 0, len(screens))
+	for i, screen := range screens {
+		buf := bytes.Buffer{}
+		if err := b.HTML(&buf, screen, opts...); err != nil {
+			return nil, fmt.Errorf("screen %d: %w", i, err)
+		}
+		res = append(res, string(leadingEmptyRe.ReplaceAll(buf.Bytes(), nil)))
+	}
+	return res, nil
+}
+
+// leadingEmptyRe matches the empty elements at the start of the HTML, such as those of
+// the replayed color codes of a screen that are followed by the first code of the screen.
+// The labels of the WithVerbatimCodes option of the empty elements are also matched.
+var leadingEmptyRe = regexp.MustCompile(`^(?:(?:` + labelRe.String() + `)?<i class="[^"]*"[^>]*></i>)+`)
+
+// blankLines returns the locations of the runs of n or more blank lines in src, which include
+// the line ending of the line before the blank lines. The blank lines may contain spaces and tabs.
+func blankLines(src []byte, n int) [][]int {
+	eol := func(i int) int {
+		switch {
+		case src[i] == '\r' && i+1 < len(src) && src[i+1] == '\n':
+			return 2
+		case src[i] == '\r', src[i] == '\n':
+			return 1
+		}
+		return 0
+	}
+	locs := [][]int{}
+	for i := 0; i < len(src); i++ {
+		l := eol(i)
+		if l == 0 {
+			continue
+		}
+		end, count := i+l, 0
+		for end < len(src) {
+			j := end
+			for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+				j++
+			}
+			if j == len(src) {
+				break
+			}
+			m := eol(j)
+			if m == 0 {
+				break
+			}
+			end = j + m
+			count++
+		}
+		if count >= n {
+			locs = append(locs, []int{i, end})
+			i = end - 1
+			continue
+		}
+		i += l - 1
+	}
+	return locs
+}
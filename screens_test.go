@@ -0,0 +1,110 @@
+package bbs_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestScreens(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want []string
+	}{
+		{"empty", "", bbs.PCBoard, []string{}},
+		{"one", "@X07Hello", bbs.PCBoard, []string{"@X07Hello"}},
+		{"clear", "@CLS@@X07Hello@CLS@world", bbs.PCBoard, []string{"@X07Hello", "@X07world"}},
+		{"form feed", "@X07Hello\f@X1Fworld\f", bbs.PCBoard, []string{"@X07Hello", "@X07@X1Fworld"}},
+		{"empty screens", "@X07Hello@CLS@\f@CLS @world", bbs.PCBoard, []string{"@X07Hello", "@X07world"}},
+		{"no codes", "Hello\fworld", bbs.PCBoard, []string{"Hello", "world"}},
+		{"renegade", "|17|03Hello\fworld", bbs.Renegade, []string{"|17|03Hello", "|17|03world"}},
+		{"celerity", "|S|b|S|wHello\f|rworld", bbs.Celerity, []string{"|S|b|S|wHello", "|S|b|S|w|rworld"}},
+		{"ansi", "\x1b[31mHello\fworld", bbs.ANSI, []string{"\x1b[31mHello", "world"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bbs.Screens([]byte(tt.src), tt.b)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Screens() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if string(got[i]) != tt.want[i] {
+					t.Errorf("Screens()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+	got := bbs.Screens([]byte("@X1FHello @X00\fworld"), bbs.PCBoard, bbs.WithTransparentX00())
+	if len(got) != 2 || string(got[1]) != "@X1Fworld" {
+		t.Errorf("Screens() with a transparent @X00 = %q, want the inherited @X1F", got)
+	}
+}
+
+func TestWithBlankLines(t *testing.T) {
+	const src = "@X07Hello\n\nthere\n\n  \r\n\t\n@X1Fworld\n\n\n\n@CLS@again"
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"default", 0, []string{"@X07Hello\n\nthere\n\n  \r\n\t\n@X1Fworld\n\n\n\n", "@X1Fagain"}},
+		{"three", 3, []string{"@X07Hello\n\nthere", "@X07@X1Fworld", "@X1Fagain"}},
+		{"four", 4, []string{"@X07Hello\n\nthere\n\n  \r\n\t\n@X1Fworld\n\n\n\n", "@X1Fagain"}},
+		{"one", 1, []string{"@X07Hello", "@X07there", "@X07@X1Fworld", "@X1Fagain"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bbs.Screens([]byte(src), bbs.PCBoard, bbs.WithBlankLines(tt.n))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Screens() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if string(got[i]) != tt.want[i] {
+					t.Errorf("Screens()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderScreens(t *testing.T) {
+	got, err := bbs.RenderScreens([]byte("@X1FHello\f@X4Eworld@CLS@again\n\n\nand again"), bbs.PCBoard,
+		bbs.WithBlankLines(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`<i class="PB1 PFF">Hello</i>`,
+		`<i class="PB4 PFE">world</i>`,
+		`<i class="PB4 PFE">again</i>`,
+		`<i class="PB4 PFE">and again</i>`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RenderScreens() = %q, want %q", got, want)
+	}
+	got, err = bbs.RenderScreens([]byte("|S|b|S|WHi\f|rthere"), bbs.Celerity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PBb PFr">there</i>`; len(got) != 2 || !strings.HasSuffix(got[1], want) {
+		t.Errorf("RenderScreens() = %q, want the last screen to end with %q", got, want)
+	}
+	got, err = bbs.RenderScreens([]byte("@X1FHello\f@X4Eworld"), bbs.PCBoard, bbs.WithTitles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<i class="PB4 PFE" title="@X4E = yellow on red">world</i>`; len(got) != 2 || got[1] != want {
+		t.Errorf("RenderScreens() = %q, want the last screen %q", got, want)
+	}
+	if _, err := bbs.RenderScreens([]byte("\x1b[0m"), bbs.ANSI); !errors.Is(err, bbs.ErrANSI) {
+		t.Errorf("RenderScreens() error = %v, want %v", err, bbs.ErrANSI)
+	}
+	if _, err := bbs.RenderScreens([]byte("@X07Hi"), bbs.PCBoard, bbs.WithPrefix("")); !errors.Is(err, bbs.ErrPrefix) {
+		t.Errorf("RenderScreens() error = %v, want %v", err, bbs.ErrPrefix)
+	}
+}
@@ -0,0 +1,26 @@
+package bbs
+
+// ColorStats returns the number of characters of src displayed in each foreground and background
+// color combination, using the same "foreground/background" color names as [DescribeColors],
+// for example "grey/black". It can tell the monochrome files from the colorful art.
+//
+// The characters are the bytes of the runs, see [BBS.Runs], so the newlines are also counted
+// and the text without a color code counts as grey on black. Empty runs are excluded.
+// ANSI, an invalid BBS or a src without any text returns nil.
+func ColorStats(src []byte, b BBS) map[string]int {
+	runs, err := b.Runs(src)
+	if err != nil {
+		return nil
+	}
+	stats := map[string]int{}
+	for _, r := range runs {
+		if len(r.Text) == 0 {
+			continue
+		}
+		stats[ColorNames[r.Foreground]+"/"+ColorNames[r.Background]] += len(r.Text)
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+	return stats
+}
@@ -0,0 +1,33 @@
+package bbs_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestColorStats(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want map[string]int
+	}{
+		{"pcboard", "Hi @X1FHello @X07world @X1F!", bbs.PCBoard,
+			map[string]int{"grey/black": 9, "white/blue": 7}},
+		{"empty runs", "@X07@X1FHello", bbs.PCBoard, map[string]int{"white/blue": 5}},
+		{"celerity", "|W|S|bHello", bbs.Celerity, map[string]int{"white/blue": 5}},
+		{"renegade", "|04Hi|20|15X", bbs.Renegade, map[string]int{"red/black": 2, "white/red": 1}},
+		{"no text", "@X07", bbs.PCBoard, nil},
+		{"ansi", "\x1b[0mHi", bbs.ANSI, nil},
+		{"invalid", "Hi", bbs.BBS(-1), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.ColorStats([]byte(tt.src), tt.b); !maps.Equal(got, tt.want) {
+				t.Errorf("ColorStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package bbs
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A StyledCell is a character of the text with its colors at a row and column of a terminal grid.
+type StyledCell struct {
+	Row        int  // Row is the zero based line of the cell.
+	Col        int  // Col is the zero based column of the cell.
+	Rune       rune // Rune is the character of the cell.
+	Foreground int  // Foreground is the CGAPalette index of the foreground color.
+	Background int  // Background is the CGAPalette index of the background color.
+}
+
+// Styles returns the characters of src as the cells of a terminal grid with their colors,
+// for painting a text user interface, such as a tcell or termbox viewer, without the HTML or ANSI.
+// The cells are expanded from the runs of text, see [BBS.Runs], in the order of the text.
+// A src that is not valid UTF-8 is decoded from CP-437, so the box drawing and block characters
+// are the Unicode runes of the cells.
+//
+// The newlines advance the rows and the tabs advance to the next tab stop of every 8 columns
+// with the space cells, while the other control characters have no cells.
+// The PCBoard, Telegard and Wildcat! backgrounds 8 to 15 are kept as their blink values.
+// ANSI, an invalid BBS or a src without any text returns nil.
+func Styles(src []byte, b BBS) []StyledCell {
+	var opts []Option
+	if !utf8.Valid(src) {
+		opts = append(opts, WithCodepage(charmap.CodePage437))
+	}
+	runs, err := b.Runs(src, opts...)
+	if err != nil {
+		return nil
+	}
+	const space = 0x20
+	var cells []StyledCell
+	row, col := 0, 0
+	for _, r := range runs {
+		for _, c := range r.Text {
+			cell := StyledCell{Row: row, Col: col, Rune: c, Foreground: r.Foreground, Background: r.Background}
+			switch {
+			case c == '\n':
+				row, col = row+1, 0
+			case c == '\t':
+				for stop := col + tabWidth - col%tabWidth; col < stop; col++ {
+					cell.Col, cell.Rune = col, space
+					cells = append(cells, cell)
+				}
+			case c < space, c == 0x7f:
+			default:
+				cells = append(cells, cell)
+				col++
+			}
+		}
+	}
+	return cells
+}
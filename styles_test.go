@@ -0,0 +1,43 @@
+package bbs_test
+
+import (
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestStyles(t *testing.T) {
+	type cell = bbs.StyledCell
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		want []bbs.StyledCell
+	}{
+		{"empty", "", bbs.PCBoard, nil},
+		{"ansi", "\x1b[0mHi", bbs.ANSI, nil},
+		{"pcboard", "A@X1FB\r\n C", bbs.PCBoard, []cell{
+			{0, 0, 'A', 7, 0}, {0, 1, 'B', 15, 1}, {1, 0, ' ', 15, 1}, {1, 1, 'C', 15, 1},
+		}},
+		{"tab", "|17|14a\tb\x07", bbs.Renegade, []cell{
+			{0, 0, 'a', 14, 1}, {0, 1, ' ', 14, 1}, {0, 2, ' ', 14, 1}, {0, 3, ' ', 14, 1},
+			{0, 4, ' ', 14, 1}, {0, 5, ' ', 14, 1}, {0, 6, ' ', 14, 1}, {0, 7, ' ', 14, 1}, {0, 8, 'b', 14, 1},
+		}},
+		{"utf-8", "@X9E╔═╗", bbs.PCBoard, []cell{{0, 0, '╔', 14, 9}, {0, 1, '═', 14, 9}, {0, 2, '╗', 14, 9}}},
+		{"cp437", "@X9E\xc9\xcd\xbb", bbs.PCBoard, []cell{{0, 0, '╔', 14, 9}, {0, 1, '═', 14, 9}, {0, 2, '╗', 14, 9}}},
+		{"wwiv heart cp437", "\x035\xdb", bbs.WWIVHeart, []cell{{0, 0, '█', 5, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bbs.Styles([]byte(tt.src), tt.b)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Styles() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Styles()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package bbs
+
+import (
+	"cmp"
+	"errors"
+	"image"
+	"image/draw"
+	"slices"
+	"unicode"
+)
+
+// ErrSwatch is returned when the width or the height of a swatch is not a positive number.
+var ErrSwatch = errors.New("swatch width and height must be positive")
+
+// Swatch returns a width by height image of the proportion of each [CGAPalette] color used by the
+// BBS color codes in src, as a horizontal strip of the dominant colors for the gallery thumbnails
+// and the metadata. The most used color is drawn on the left, and the width of each color
+// is proportional to its number of characters, so a color used by very few characters can be omitted.
+//
+// Like [ColorStats] the characters are counted from the runs, see [BBS.Runs], but each character
+// counts as the color that is seen, the foreground of the printable characters and the background
+// of the spaces, while the control characters such as the newlines are not counted.
+// The PCBoard, Telegard and Wildcat! blinking backgrounds 8 to 15 count as the backgrounds 0 to 7.
+// ANSI or an invalid BBS is an error, and a src without any characters returns [ErrNone].
+func Swatch(src []byte, b BBS, width, height int) (image.Image, error) {
+	if width < 1 || height < 1 {
+		return nil, ErrSwatch
+	}
+	runs, err := b.Runs(src)
+	if err != nil {
+		return nil, err
+	}
+	counts, total := [len(CGAPalette)]int{}, 0
+	for _, r := range runs {
+		for _, c := range r.Text {
+			switch {
+			case unicode.IsControl(c):
+				continue
+			case unicode.IsSpace(c):
+				counts[b.shown(r.Background, false)]++
+			default:
+				counts[r.Foreground]++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return nil, ErrNone
+	}
+	order := make([]int, 0, len(counts))
+	for i, n := range counts {
+		if n > 0 {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(counts[b], counts[a])
+	})
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	x, sum := 0, 0
+	for _, i := range order {
+		sum += counts[i]
+		// the cumulative rounding always fills the full width
+		end := (sum*width + total/2) / total
+		draw.Draw(img, image.Rect(x, 0, end, height), image.NewUniform(CGAPalette[i]), image.Point{}, draw.Src)
+		x = end
+	}
+	return img, nil
+}
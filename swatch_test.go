@@ -0,0 +1,65 @@
+package bbs_test
+
+import (
+	"errors"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestSwatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		b     bbs.BBS
+		width int
+		want  []int
+	}{
+		{"pcboard", "@X1FABC  \n@X0EX", bbs.PCBoard, 6, []int{15, 15, 15, 1, 1, 14}},
+		{"same count", "@X1FAB  ", bbs.PCBoard, 4, []int{1, 1, 15, 15}},
+		{"blink", "@X9F  ", bbs.PCBoard, 2, []int{1, 1}},
+		{"celerity", "|W|S|bHi", bbs.Celerity, 3, []int{15, 15, 15}},
+		{"renegade", "|04Hi|20|15X", bbs.Renegade, 3, []int{4, 4, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := bbs.Swatch([]byte(tt.src), tt.b, tt.width, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Dx(); got != tt.width {
+				t.Fatalf("Swatch() width = %d, want %d", got, tt.width)
+			}
+			got := make([]int, tt.width)
+			for x := range got {
+				c := color.RGBAModel.Convert(img.At(x, 1)).(color.RGBA)
+				got[x] = slices.Index(bbs.CGAPalette[:], c)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Swatch() colors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	errs := []struct {
+		name   string
+		src    string
+		b      bbs.BBS
+		width  int
+		height int
+		want   error
+	}{
+		{"no width", "@X1FHi", bbs.PCBoard, 0, 2, bbs.ErrSwatch},
+		{"no height", "@X1FHi", bbs.PCBoard, 2, -1, bbs.ErrSwatch},
+		{"no text", "@X1F\r\n", bbs.PCBoard, 2, 2, bbs.ErrNone},
+		{"ansi", "\x1b[0mHi", bbs.ANSI, 2, 2, bbs.ErrANSI},
+	}
+	for _, tt := range errs {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := bbs.Swatch([]byte(tt.src), tt.b, tt.width, tt.height); !errors.Is(err, tt.want) {
+				t.Errorf("Swatch() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package bbs
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// cgaToANSI maps the CGAPalette indexes 0 to 7 to the ANSI color numbers.
+var cgaToANSI = [8]int{0, 4, 2, 6, 1, 5, 3, 7}
+
+// reset is the ANSI select graphic rendition sequence that resets the colors.
+const reset = "\x1b[0m"
+
+// Terminal writes to buf the BBS color codes of src as ANSI select graphic rendition color sequences,
+// so the text can be displayed in a terminal. The high intensity foregrounds use the bold attribute,
+// while the backgrounds 8 to 15 use the blink attribute, the same as a PC/MS-DOS text mode display,
+// or the bright background colors when the iCE colors are used, see [WithBlink].
+//
+// The output ends with a reset sequence, so the last color does not bleed into the text that follows
+// the output in a terminal. The reset can be disabled with the [WithEndReset] option.
+func (b BBS) Terminal(buf *bytes.Buffer, src []byte, opts ...Option) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	runs, err := b.Runs(src, opts...)
+	if err != nil {
+		return err
+	}
+	w := bytes.Buffer{}
+	ice := newConfig(opts...).ice(src)
+	last, colored := "", false
+	for _, r := range runs {
+		if r.Text == "" {
+			continue
+		}
+		if sgr := sgrColors(r.Foreground, r.Background, ice); sgr != last {
+			w.WriteString(sgr)
+			last, colored = sgr, true
+		}
+		w.WriteString(r.Text)
+	}
+	if colored && !newConfig(opts...).noEndReset {
+		w.WriteString(reset)
+	}
+	_, err = buf.Write(w.Bytes())
+	return err
+}
+
+// sgrColors returns the ANSI select graphic rendition sequence of the CGAPalette colors.
+// The backgrounds 8 to 15 either blink or with ice, use the bright background colors.
+func sgrColors(fg, bg int, ice bool) string {
+	const bright = 8
+	codes := []string{"0"}
+	if fg >= bright {
+		codes = append(codes, "1")
+	}
+	background := 40
+	switch {
+	case bg >= bright && ice:
+		background = 100
+	case bg >= bright:
+		codes = append(codes, "5")
+	}
+	codes = append(codes,
+		strconv.Itoa(30+cgaToANSI[fg%bright]),
+		strconv.Itoa(background+cgaToANSI[bg%bright]))
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
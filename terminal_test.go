@@ -0,0 +1,52 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestBBS_Terminal(t *testing.T) {
+	if err := bbs.PCBoard.Terminal(nil, nil); !errors.Is(err, bbs.ErrBuff) {
+		t.Errorf("BBS.Terminal() error = %v, want %v", err, bbs.ErrBuff)
+	}
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		want string
+	}{
+		{"plain", bbs.PCBoard, "Hello", "\x1b[0;37;40mHello\x1b[0m"},
+		{"empty", bbs.PCBoard, "", ""},
+		{"pcboard", bbs.PCBoard, "@X07Hello @X1Cworld",
+			"\x1b[0;37;40mHello \x1b[0;1;31;44mworld\x1b[0m"},
+		{"blink", bbs.PCBoard, "@X81Hi", "\x1b[0;5;34;40mHi\x1b[0m"},
+		{"same colors", bbs.Renegade, "|07Hello |07world", "\x1b[0;37;40mHello world\x1b[0m"},
+		{"celerity", bbs.Celerity, "|bHello", "\x1b[0;34;40mHello\x1b[0m"},
+		{"wwiv", bbs.WWIVHash, "|#2Hello\r\n", "\x1b[0;32;40mHello\n\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := tt.b.Terminal(&buf, []byte(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("BBS.Terminal() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.PCBoard.Terminal(&buf, []byte("@X0EHello"), bbs.WithEndReset(false)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasSuffix(buf.String(), "\x1b[0m") {
+		t.Errorf("BBS.Terminal() = %q, want no end reset", buf.String())
+	}
+	if err := bbs.ANSI.Terminal(&buf, []byte("\x1b[0m")); !errors.Is(err, bbs.ErrANSI) {
+		t.Errorf("BBS.Terminal() error = %v, want %v", err, bbs.ErrANSI)
+	}
+}
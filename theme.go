@@ -0,0 +1,113 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Theme errors.
+var (
+	ErrTheme = errors.New("theme is not valid")
+)
+
+// A Theme is a palette variant that re-skins the HTML using the CSS custom properties.
+type Theme int
+
+// Themes of the palette colors.
+const (
+	Classic Theme = iota // Classic is the standard CGA palette.
+	Amber                // Amber is a monochrome amber CRT monitor palette.
+	Green                // Green is a monochrome green CRT monitor palette.
+)
+
+// Palette returns the 16 colors of the theme, indexed the same as the [CGAPalette].
+// The monochrome themes shade the phosphor color by the brightness of each CGA color.
+func (t Theme) Palette() [16]color.RGBA {
+	switch t {
+	case Amber:
+		return monochrome(color.RGBA{0xff, 0xb0, 0x00, 0xff})
+	case Green:
+		return monochrome(color.RGBA{0x33, 0xff, 0x33, 0xff})
+	default:
+		return CGAPalette
+	}
+}
+
+// String returns the name of the theme.
+func (t Theme) String() string {
+	if !t.Valid() {
+		return ""
+	}
+	return [...]string{"classic", "amber", "green"}[t]
+}
+
+// Valid reports whether the theme is valid.
+func (t Theme) Valid() bool {
+	return t >= Classic && t <= Green
+}
+
+// monochrome returns the CGA palette shaded using the phosphor color.
+// Black remains black, while the other colors have a minimum brightness so they are visible.
+func monochrome(phosphor color.RGBA) [16]color.RGBA {
+	const floor, luma = 0.3, 0.7
+	var p [16]color.RGBA
+	for i, c := range CGAPalette {
+		if i == 0 {
+			p[i] = c
+			continue
+		}
+		y := (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 0xff
+		y = floor + luma*y
+		p[i] = color.RGBA{
+			R: uint8(float64(phosphor.R) * y),
+			G: uint8(float64(phosphor.G) * y),
+			B: uint8(float64(phosphor.B) * y),
+			A: 0xff,
+		}
+	}
+	return p
+}
+
+// palette returns the palette colors of the theme scaled by the brightness factor.
+func (c config) palette() [16]color.RGBA {
+	p := c.theme.Palette()
+	if c.bright == 1 {
+		return p
+	}
+	scale := func(v uint8) uint8 {
+		return uint8(math.Min(math.Round(float64(v)*c.bright), 0xff))
+	}
+	for i, rgb := range p {
+		p[i] = color.RGBA{R: scale(rgb.R), G: scale(rgb.G), B: scale(rgb.B), A: rgb.A}
+	}
+	return p
+}
+
+// CSSTheme writes to buf the CSS :root custom properties of the palette colors of the theme.
+// The same HTML and color classes can then be re-skinned by replacing these properties.
+func (b BBS) CSSTheme(buf *bytes.Buffer, theme Theme) error {
+	if buf == nil {
+		return ErrBuff
+	}
+	if !theme.Valid() {
+		return ErrTheme
+	}
+	w := bytes.Buffer{}
+	fmt.Fprint(&w, ":root {\n")
+	for i, rgb := range theme.Palette() {
+		fmt.Fprintf(&w, "  --%s: rgb(%d, %d, %d);\n", ColorNames[i], rgb.R, rgb.G, rgb.B)
+	}
+	fmt.Fprint(&w, "}\n")
+	_, err := buf.Write(w.Bytes())
+	return err
+}
+
+// WithTheme sets the palette colors used by the generated CSS, the default is Classic.
+func WithTheme(theme Theme) Option {
+	return func(c *config) {
+		c.theme = theme
+	}
+}
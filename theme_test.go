@@ -0,0 +1,100 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestBBS_CSSTheme(t *testing.T) {
+	if err := bbs.PCBoard.CSSTheme(nil, bbs.Classic); !errors.Is(err, bbs.ErrBuff) {
+		t.Errorf("BBS.CSSTheme() error = %v, want %v", err, bbs.ErrBuff)
+	}
+	buf := bytes.Buffer{}
+	if err := bbs.PCBoard.CSSTheme(&buf, 99); !errors.Is(err, bbs.ErrTheme) {
+		t.Errorf("BBS.CSSTheme() error = %v, want %v", err, bbs.ErrTheme)
+	}
+	tests := []struct {
+		theme bbs.Theme
+		want  string
+	}{
+		{bbs.Classic, "--lightblue: rgb(85, 85, 255);"},
+		{bbs.Amber, "--white: rgb(255, 176, 0);"},
+		{bbs.Green, "--white: rgb(51, 255, 51);"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.theme.String(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := bbs.PCBoard.CSSTheme(&buf, tt.theme); err != nil {
+				t.Fatal(err)
+			}
+			css := buf.String()
+			if !strings.HasPrefix(css, ":root {") {
+				t.Errorf("BBS.CSSTheme() = %q, want a :root rule", css)
+			}
+			if !strings.Contains(css, "--black: rgb(0, 0, 0);") {
+				t.Errorf("BBS.CSSTheme() = %q, want a black property", css)
+			}
+			if !strings.Contains(css, tt.want) {
+				t.Errorf("BBS.CSSTheme() = %q, want %q", css, tt.want)
+			}
+			gen := bytes.Buffer{}
+			if err := bbs.GenerateCSS(&gen, bbs.WithTheme(tt.theme)); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(gen.String(), tt.want) {
+				t.Errorf("GenerateCSS() is missing %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestTheme_Palette(t *testing.T) {
+	if bbs.Classic.Palette() != bbs.CGAPalette {
+		t.Error("Classic.Palette() does not match the CGAPalette")
+	}
+	for _, theme := range []bbs.Theme{bbs.Amber, bbs.Green} {
+		p := theme.Palette()
+		for i := 1; i < len(p); i++ {
+			if p[i] == p[0] {
+				t.Errorf("%s.Palette()[%d] is invisible on black", theme, i)
+			}
+		}
+	}
+}
+
+func TestWithBrightness(t *testing.T) {
+	buf := bytes.Buffer{}
+	for _, factor := range []float64{-0.5, math.NaN(), math.Inf(1)} {
+		if err := bbs.GenerateCSS(&buf, bbs.WithBrightness(factor)); !errors.Is(err, bbs.ErrBrightness) {
+			t.Errorf("GenerateCSS() error = %v, want %v", err, bbs.ErrBrightness)
+		}
+	}
+	tests := []struct {
+		name   string
+		factor float64
+		want   []string
+	}{
+		{"default", 1, []string{"--lightblue: rgb(85, 85, 255);", "--white: rgb(255, 255, 255);"}},
+		{"dim", 0.8, []string{"--lightblue: rgb(68, 68, 204);", "--white: rgb(204, 204, 204);"}},
+		{"bright", 1.2, []string{"--lightblue: rgb(102, 102, 255);", "--grey: rgb(204, 204, 204);"}},
+		{"off", 0, []string{"--white: rgb(0, 0, 0);"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := bbs.GenerateCSS(&buf, bbs.WithBrightness(tt.factor)); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("GenerateCSS() is missing %q", want)
+				}
+			}
+		})
+	}
+}
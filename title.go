@@ -0,0 +1,77 @@
+package bbs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bengarrett/bbs/internal/split"
+)
+
+// WithTitles adds a title attribute to each color element that describes the color code of the format
+// and its colors, for example title="@X1F = white on blue", which is shown as a tooltip when hovering
+// over the text. It turns the HTML into a teaching tool for the legacy formats, but it bloats the output.
+// The colors are the names of the [ColorNames], and the PCBoard, Telegard and Wildcat! blinking
+// backgrounds are described as blinking. The code of the Renegade and WWIV elements are the codes of
+// their current colors, as these formats set the background and the foreground with separate codes.
+func WithTitles() Option {
+	return func(c *config) {
+		c.titles = true
+	}
+}
+
+// title returns the title attribute function of the format, or nil when the titles are not used.
+func (c config) title(b BBS) func(bg, fg string) string {
+	if !c.titles {
+		return nil
+	}
+	return func(bg, fg string) string {
+		const blink = 8
+		code := b.code(bg, fg)
+		if code == "" {
+			return ""
+		}
+		ice := strings.HasPrefix(bg, split.IceBackground)
+		f, g := b.index(fg), b.index(bg)
+		colors := ColorNames[f] + " on " + ColorNames[b.shown(g, ice)]
+		switch b {
+		case PCBoard, Telegard, Wildcat:
+			if g >= blink && !ice {
+				colors += ", blinking"
+			}
+		}
+		return code + " = " + colors
+	}
+}
+
+// code returns the color code of the format for the background and foreground color values of a run,
+// or an empty string for an unsupported format. The Renegade and WWIV codes are of the current colors.
+func (b BBS) code(bg, fg string) string {
+	raw := strings.TrimPrefix(bg, split.IceBackground)
+	switch b {
+	case PCBoard:
+		return "@X" + raw + fg
+	case Telegard:
+		return "`" + raw + fg
+	case Wildcat:
+		return "@" + raw + fg + "@"
+	case Renegade:
+		const firstBackground = 16
+		code := fmt.Sprintf("|%02d", b.index(fg))
+		if n, _ := strconv.Atoi(bg); n >= firstBackground {
+			code = fmt.Sprintf("|%d%s", n, code)
+		}
+		return code
+	case WWIVHash:
+		return "|#" + fg
+	case WWIVHeart:
+		return "♥" + fg
+	case Celerity:
+		code := "|" + fg
+		if bg != "k" {
+			code = "|S|" + bg + "|S" + code
+		}
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package bbs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestWithTitles(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		opts []bbs.Option
+		want string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X1FHello", nil,
+			`Hi <i class="PB1 PFF" title="@X1F = white on blue">Hello</i>`},
+		{"pcboard blink", bbs.PCBoard, "@X9eHello", nil,
+			`<i class="PB9 PFE" title="@X9E = yellow on blue, blinking">Hello</i>`},
+		{"pcboard ice", bbs.PCBoard, "@X9EHello", []bbs.Option{bbs.WithBlink(bbs.BlinkIce)},
+			`<i class="PBI9 PFE" title="@X9E = yellow on lightblue">Hello</i>`},
+		{"telegard", bbs.Telegard, "`1FHello", nil,
+			`<i class="PB1 PFF" title="` + "`" + `1F = white on blue">Hello</i>`},
+		{"wildcat", bbs.Wildcat, "@1F@Hello", nil,
+			`<i class="PB1 PFF" title="@1F@ = white on blue">Hello</i>`},
+		{"renegade", bbs.Renegade, "|07Hi |17|15Hello", nil,
+			`<i class="P0 P7" title="|07 = grey on black">Hi </i><i class="P17 P7" title="|17|07 = grey on blue"></i>` +
+				`<i class="P17 P15" title="|17|15 = white on blue">Hello</i>`},
+		{"wwiv hash", bbs.WWIVHash, "|#3Hello", nil,
+			`<i class="P0 P3" title="|#3 = cyan on black">Hello</i>`},
+		{"wwiv heart", bbs.WWIVHeart, "\x033Hello", nil,
+			`<i class="P0 P3" title="♥3 = cyan on black">Hello</i>`},
+		{"celerity", bbs.Celerity, "|WHi|S|b|S|yHello", nil,
+			`<i class="PBk PFW" title="|W = white on black">Hi</i><i class="PBb PFW" title="|S|b|S|W = white on blue"></i>` +
+				`<i class="PBb PFy" title="|S|b|S|y = brown on blue">Hello</i>`},
+		{"escaped", bbs.PCBoard, "@X07<Hi>", []bbs.Option{bbs.WithUnsafeNoEscape()},
+			`<i class="PB0 PF7" title="@X07 = grey on black"><Hi></i>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), append(tt.opts, bbs.WithTitles())...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTitles_parsers(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X07there @X1F<blue>\n@X07!"},
+		{"celerity", bbs.Celerity, "Hi |rthere|S|b blue |S|Wwhite"},
+		{"renegade", bbs.Renegade, "Hi |07there |17|15blue|03!"},
+		{"wildcat", bbs.Wildcat, "Hi @07@there @1F@blue"},
+		{"wwiv hash", bbs.WWIVHash, "Hi |#7there |#3blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src), bbs.WithTitles()); err != nil {
+				t.Fatal(err)
+			}
+			if !bbs.IsRendered(html.Bytes()) {
+				t.Error("IsRendered() = false, want true")
+			}
+			got, err := bbs.FromHTML(html.Bytes(), tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.src {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.src)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package bbs
+
+import (
+	"unicode/utf8"
+)
+
+// resets are the color codes of the BBS formats that reset the colors to grey on black.
+var resets = [...]string{
+	ANSI:      reset,
+	PCBoard:   "@X07",
+	Renegade:  "|16|07",
+	Telegard:  "`07",
+	Wildcat:   "@07@",
+	WWIVHash:  "|#7",
+	WWIVHeart: "\x037",
+}
+
+// Truncate returns the prefix of src with at most maxVisible visible characters, for the previews
+// and the width limits of the BBS text. The cut is always on a rune boundary and never splits
+// a color code or a multibyte UTF-8 sequence. The color codes before the cut are kept,
+// so the prefix has the same colors as src, while the codes that directly follow
+// the last visible character are removed, as they color nothing.
+// A reset code of the format, that returns the colors to grey on black, is appended to the prefix.
+//
+// The visible characters are the runes other than the control characters, such as the newlines,
+// and the bytes of invalid UTF-8 that are each a CP-437 character. The control characters
+// that follow the last visible character are also removed.
+// If src has no more than maxVisible visible characters it is returned unchanged,
+// and an invalid BBS treats src as plain text.
+func Truncate(src []byte, b BBS, maxVisible int) []byte {
+	if maxVisible < 0 {
+		maxVisible = 0
+	}
+	var locs [][]int
+	if b.Valid() {
+		locs = b.Regexp().FindAllSubmatchIndex(src, -1)
+	}
+	visible, end, codes, kept := 0, 0, 0, 0
+	for i := 0; i < len(src); {
+		if len(locs) > 0 && locs[0][0] == i {
+			i = locs[0][1]
+			locs = locs[1:]
+			codes++
+			continue
+		}
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size <= 1 {
+			size = 1
+		} else if r < ' ' || r == 0x7f {
+			i += size
+			continue
+		}
+		if visible == maxVisible {
+			return append(src[:end:end], truncReset(src[:end], b, kept)...)
+		}
+		visible++
+		i += size
+		end, kept = i, codes
+	}
+	return src
+}
+
+// truncReset returns the reset code of the format to append to the truncated src,
+// or nothing when src has no color codes.
+func truncReset(src []byte, b BBS, codes int) string {
+	if codes == 0 || !b.Valid() {
+		return ""
+	}
+	if b != Celerity {
+		return resets[b]
+	}
+	// the Celerity |d default color resets the foreground, or the background when swapped
+	const swapCmd = 'S'
+	swapped := false
+	for _, m := range Celerity.Regexp().FindAllSubmatchIndex(src, -1) {
+		if src[m[2]] == swapCmd {
+			swapped = !swapped
+		}
+	}
+	if swapped {
+		return "|d|S|d"
+	}
+	return "|S|d|S|d"
+}
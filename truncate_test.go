@@ -0,0 +1,44 @@
+package bbs_test
+
+import (
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		b    bbs.BBS
+		max  int
+		want string
+	}{
+		{"short", "@X1FHello", bbs.PCBoard, 5, "@X1FHello"},
+		{"cut", "@X1FHello world", bbs.PCBoard, 5, "@X1FHello@X07"},
+		{"code boundary", "@X1FHello@X4Eworld", bbs.PCBoard, 5, "@X1FHello@X07"},
+		{"code after boundary", "@X1FHell@X4Eoworld", bbs.PCBoard, 5, "@X1FHell@X4Eo@X07"},
+		{"no codes", "Hello world", bbs.PCBoard, 5, "Hello"},
+		{"codes after cut", "Hello @X1Fworld", bbs.PCBoard, 5, "Hello"},
+		{"zero", "@X1FHello", bbs.PCBoard, 0, ""},
+		{"newlines", "@X1FHel\nlo\nworld", bbs.PCBoard, 5, "@X1FHel\nlo@X07"},
+		{"multibyte", "@X1F╔═══╗", bbs.PCBoard, 2, "@X1F╔═@X07"},
+		{"cp437", "@X1F\xc9\xcd\xcd\xbb", bbs.PCBoard, 2, "@X1F\xc9\xcd@X07"},
+		{"renegade", "|17|15Hello", bbs.Renegade, 2, "|17|15He|16|07"},
+		{"telegard", "`1FHello", bbs.Telegard, 2, "`1FHe`07"},
+		{"wildcat", "@1F@Hello", bbs.Wildcat, 2, "@1F@He@07@"},
+		{"wwiv hash", "|#5Hello", bbs.WWIVHash, 2, "|#5He|#7"},
+		{"wwiv heart", "♥5Hello", bbs.WWIVHeart, 2, "♥5He\x037"},
+		{"celerity", "|WHello", bbs.Celerity, 2, "|WHe|S|d|S|d"},
+		{"celerity swapped", "|S|bHello", bbs.Celerity, 2, "|S|bHe|d|S|d"},
+		{"ansi", "\x1b[1;37mHello", bbs.ANSI, 2, "\x1b[1;37mHe\x1b[0m"},
+		{"invalid", "@X1FHello", -1, 2, "@X"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbs.Truncate([]byte(tt.src), tt.b, tt.max); string(got) != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
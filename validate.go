@@ -0,0 +1,56 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrMarkup is returned when the HTML output is not well-formed.
+var ErrMarkup = errors.New("html output is not well-formed")
+
+// tagRe matches a HTML start or end tag with double quoted attributes.
+var tagRe = regexp.MustCompile(`^<(/?)([a-z][a-z0-9]*)((?:\s+[a-z][a-z0-9-]*="[^"<>]*")*)\s*(/?)>`)
+
+// voids are the elements without content that have no end tag.
+var voids = map[string]bool{"br": true, "hr": true, "img": true, "wbr": true}
+
+// ValidateOutput returns an [ErrMarkup] error if the HTML created by the renderers is not well-formed.
+// Every < must begin a tag with double quoted attributes, and every element must be closed in order,
+// except for the void elements such as <br> and <wbr>. The text content is otherwise not checked.
+//
+// It is intended for tests and for verifying the output when using custom templates or options,
+// it is not a general purpose HTML validator.
+func ValidateOutput(html []byte) error {
+	open := []string{}
+	offset := 0
+	for {
+		i := bytes.IndexByte(html[offset:], '<')
+		if i < 0 {
+			break
+		}
+		offset += i
+		m := tagRe.FindSubmatch(html[offset:])
+		if m == nil {
+			return fmt.Errorf("%w: malformed tag at offset %d", ErrMarkup, offset)
+		}
+		end, name, self := len(m[1]) > 0, string(m[2]), len(m[4]) > 0
+		switch {
+		case end && (voids[name] || len(m[3]) > 0 || self):
+			return fmt.Errorf("%w: invalid end tag </%s> at offset %d", ErrMarkup, name, offset)
+		case end:
+			if len(open) == 0 || open[len(open)-1] != name {
+				return fmt.Errorf("%w: unexpected end tag </%s> at offset %d", ErrMarkup, name, offset)
+			}
+			open = open[:len(open)-1]
+		case !voids[name] && !self:
+			open = append(open, name)
+		}
+		offset += len(m[0])
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%w: unclosed <%s> element", ErrMarkup, open[len(open)-1])
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package bbs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+func TestValidateOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		ok   bool
+	}{
+		{"empty", "", true},
+		{"text", "Hello world &amp; &gt;", true},
+		{"element", `<i class="PB0 PF7">Hello</i> world`, true},
+		{"nested", `<i class="P7"><i class="P1">Hi</i></i>`, true},
+		{"void", `<i class="P7">Hi<wbr data-bbs="swap"></i><br class="Ppage">`, true},
+		{"dangling", `<i class="PB0 PF7">Hello</i><i`, false},
+		{"unclosed", `<i class="PB0 PF7">Hello`, false},
+		{"unopened", `Hello</i>`, false},
+		{"misnested", `<i class="P7"><b>Hi</i></b>`, false},
+		{"unquoted", `<i class=P7>Hi</i>`, false},
+		{"quote", `<i class="P7>Hi</i>`, false},
+		{"void end", `<br class="Ppage"></br>`, false},
+		{"less than", `1 < 2`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bbs.ValidateOutput([]byte(tt.html))
+			if tt.ok && err != nil {
+				t.Errorf("ValidateOutput() error = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, bbs.ErrMarkup) {
+				t.Errorf("ValidateOutput() error = %v, want %v", err, bbs.ErrMarkup)
+			}
+		})
+	}
+}
+
+// TestValidateOutput_fixtures renders every fixture in testdata with every renderer.
+func TestValidateOutput_fixtures(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples, err := filepath.Glob(filepath.Join("static", "examples", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = append(names, examples...)
+	opts := [][]bbs.Option{
+		nil,
+		{bbs.WithPrefix("bbs-"), bbs.WithPageBreak()},
+		{bbs.WithMarkers(), bbs.WithBareReset()},
+	}
+	for _, name := range names {
+		if ext := filepath.Ext(name); ext == ".html" || ext == ".md" {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		utf8, err := io.ReadAll(transform.NewReader(bytes.NewReader(src), charmap.CodePage437.NewDecoder()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, opt := range opts {
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, bytes.NewReader(utf8), opt...); err != nil {
+				t.Fatal(err)
+			}
+			if err := bbs.ValidateOutput(buf.Bytes()); err != nil {
+				t.Errorf("HTML() of %s: %v", name, err)
+			}
+			for b := bbs.Celerity; b.Valid(); b++ {
+				buf := bytes.Buffer{}
+				if err := b.HTML(&buf, utf8, opt...); err != nil {
+					t.Fatal(err)
+				}
+				if err := bbs.ValidateOutput(buf.Bytes()); err != nil {
+					t.Errorf("%s BBS.HTML() of %s: %v", b.Name(), name, err)
+				}
+			}
+		}
+	}
+}
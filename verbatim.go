@@ -0,0 +1,36 @@
+package bbs
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithVerbatimCodes writes the color code of each color element as a visible label before its text,
+// for example <code class="Pcode">@X1F</code><i class="PB1 PFF">Hello</i>, while the text keeps its colors.
+// It is a debugging and teaching render that shows exactly where the codes are in the source,
+// and [GenerateCSS] with the option adds the style of the labels, that are yellow badges.
+// The labels are the codes of the current colors in the notation of the format, like [WithTitles],
+// so a Renegade |15 code after a |17 code is labeled |17|15.
+// The labels add text to the columns of the art, and they are skipped by [FromHTML].
+func WithVerbatimCodes() Option {
+	return func(c *config) {
+		c.verbatim = true
+	}
+}
+
+// codeLabel returns the label function of the color codes of the format, or nil when the labels are not used.
+func (c config) codeLabel(b BBS) func(bg, fg string) string {
+	if !c.verbatim {
+		return nil
+	}
+	return b.code
+}
+
+// verbatimCSS writes the class of the color code labels.
+func (c config) verbatimCSS(w io.Writer) {
+	if !c.verbatim {
+		return
+	}
+	fmt.Fprintf(w, "\n/* Color code labels */\n\ncode.%scode {\n  background-color: var(--yellow);\n"+
+		"  border-radius: 0.25em;\n  color: var(--black);\n  font-size: 0.75em;\n  margin-right: 0.125em;\n}\n", c.prefix)
+}
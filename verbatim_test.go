@@ -0,0 +1,101 @@
+package bbs_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+func TestWithVerbatimCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+		opts []bbs.Option
+		want string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X1FHello @x07", nil,
+			`Hi <code class="Pcode">@X1F</code><i class="PB1 PFF">Hello </i>`},
+		{"renegade", bbs.Renegade, "|17|15Hi", nil,
+			`<code class="Pcode">|17|00</code><i class="P17 P0"></i><code class="Pcode">|17|15</code><i class="P17 P15">Hi</i>`},
+		{"wwiv heart", bbs.WWIVHeart, "\x033Hello", nil,
+			`<code class="Pcode">♥3</code><i class="P0 P3">Hello</i>`},
+		{"telegard", bbs.Telegard, "`1F<Hi>", nil,
+			`<code class="Pcode">` + "`" + `1F</code><i class="PB1 PFF">&lt;Hi&gt;</i>`},
+		{"prefix", bbs.PCBoard, "@X1FHi", []bbs.Option{bbs.WithPrefix("bbs-")},
+			`<code class="bbs-code">@X1F</code><i class="bbs-B1 bbs-FF">Hi</i>`},
+		{"line ends", bbs.PCBoard, "@X1FHello\nworld", []bbs.Option{bbs.WithLineEnds()},
+			`<code class="Pcode">@X1F</code><i class="PB1 PFF">Hello</i>` + "\n" + `<i class="PB1 PFF">world</i>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Buffer{}
+			if err := tt.b.HTML(&got, []byte(tt.src), append(tt.opts, bbs.WithVerbatimCodes())...); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BBS.HTML() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+	t.Run("css", func(t *testing.T) {
+		css := bytes.Buffer{}
+		if err := bbs.GenerateCSS(&css, bbs.WithVerbatimCodes()); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(css.String(), "code.Pcode {") {
+			t.Error("GenerateCSS() does not contain the code.Pcode class")
+		}
+		css.Reset()
+		if err := bbs.GenerateCSS(&css); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(css.String(), "code.Pcode {") {
+			t.Error("GenerateCSS() contains the code.Pcode class without the option")
+		}
+	})
+}
+
+func TestWithVerbatimCodes_parsers(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bbs.BBS
+		src  string
+	}{
+		{"pcboard", bbs.PCBoard, "Hi @X07there @X1F<blue>\n@X07!"},
+		{"celerity", bbs.Celerity, "Hi |rthere|S|b blue |S|Wwhite"},
+		{"renegade", bbs.Renegade, "Hi |07there |17|15blue|03!"},
+		{"telegard", bbs.Telegard, "Hi `07there `1Fblue"},
+		{"wwiv heart", bbs.WWIVHeart, "Hi \x037there \x033blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []bbs.Option{bbs.WithVerbatimCodes(), bbs.WithTitles()}
+			html := bytes.Buffer{}
+			if err := tt.b.HTML(&html, []byte(tt.src), opts...); err != nil {
+				t.Fatal(err)
+			}
+			got, err := bbs.FromHTML(html.Bytes(), tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.src {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.src)
+			}
+			want := bbs.UsedClasses([]byte(tt.src), tt.b)
+			if used := bbs.UsedClasses([]byte(tt.src), tt.b, opts...); !slices.Equal(used, want) {
+				t.Errorf("UsedClasses() = %q, want %q", used, want)
+			}
+		})
+	}
+	got, err := bbs.RenderScreens([]byte("@X1FHello\f@X4Eworld"), bbs.PCBoard, bbs.WithVerbatimCodes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<code class="Pcode">@X4E</code><i class="PB4 PFE">world</i>`; len(got) != 2 || got[1] != want {
+		t.Errorf("RenderScreens() = %q, want the last screen %q", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package bbs_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bengarrett/bbs"
+)
+
+// wellFormed returns an error if the fragment is not well-formed XML.
+func wellFormed(fragment []byte) error {
+	d := xml.NewDecoder(io.MultiReader(
+		strings.NewReader("<div>"), bytes.NewReader(fragment), strings.NewReader("</div>")))
+	d.Strict = true
+	for {
+		if _, err := d.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func TestWithXHTML(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.[pt][cx][bt]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []bbs.Option{bbs.WithXHTML(), bbs.WithMarkers(), bbs.WithPageBreak(), bbs.WithBareReset()}
+	for _, name := range files {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if _, err := bbs.HTML(&buf, bytes.NewReader(src), opts...); err != nil {
+				t.Fatal(err)
+			}
+			if err := wellFormed(buf.Bytes()); err != nil {
+				t.Errorf("HTML() is not well-formed XML: %s", err)
+			}
+		})
+	}
+	const src = "|S|b|WHello\f@CLS@world|!"
+	buf := bytes.Buffer{}
+	if err := bbs.Celerity.HTML(&buf, []byte(src), opts...); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<wbr data-bbs="swap" />`, `<br class="Ppage" />`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("BBS.HTML() = %q, want %q", buf.String(), want)
+		}
+	}
+	if err := wellFormed(buf.Bytes()); err != nil {
+		t.Errorf("BBS.HTML() is not well-formed XML: %s", err)
+	}
+	if err := bbs.ValidateOutput(buf.Bytes()); err != nil {
+		t.Error(err)
+	}
+	back, err := bbs.FromHTML(buf.Bytes(), bbs.Celerity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(back), "|S") {
+		t.Errorf("FromHTML() = %q, want the |S swap", back)
+	}
+	buf.Reset()
+	if err := bbs.Renegade.HTML(&buf, []byte("|07Hi\x1a\x01\x02"), bbs.WithXHTML()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wellFormed(buf.Bytes()); err != nil {
+		t.Errorf("BBS.HTML() = %q is not well-formed XML: %s", buf.String(), err)
+	}
+}